@@ -0,0 +1,99 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+//go:embed worker-template/*
+var workerTemplate embed.FS
+
+const workerDir = "src/core/application/worker"
+
+// workerNamePattern keeps worker names valid in the Go identifiers they are turned into
+var workerNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// AddWorker generates a scheduled job file and registers it in the generated scheduler
+func AddWorker(wd, packageName string, args []string) error {
+	flags := flag.NewFlagSet("add worker", flag.ExitOnError)
+	name := flags.String("name", "", "Name of the worker (e.g., cleanup)")
+	schedule := flags.String("schedule", "", "Cron expression or interval (e.g., \"0 3 * * *\", \"@every 10m\", \"10m\")")
+	leader := flags.Bool("leader", false, "Run the job on a single replica using a Postgres advisory lock")
+	flags.Parse(args)
+
+	if *name == "" || *schedule == "" {
+		return fmt.Errorf("both '-name' and '-schedule' are required")
+	}
+	if !workerNamePattern.MatchString(*name) {
+		return fmt.Errorf("invalid name %q, use letters, digits and underscores, starting with a letter", *name)
+	}
+	if err := validateSchedule(*schedule); err != nil {
+		return err
+	}
+
+	schedulerPath := filepath.Join(wd, workerDir, "scheduler.go")
+	if _, err := os.Stat(schedulerPath); err != nil {
+		return fmt.Errorf("%s not found, generate the service with gStructify first", schedulerPath)
+	}
+
+	workerName := snakeToCamelCase(CamelToSnake(*name))
+	workerPath := filepath.Join(wd, workerDir, CamelToSnake(*name)+"_worker.go")
+	if _, err := os.Stat(workerPath); err == nil {
+		return fmt.Errorf("worker %s already exists", workerPath)
+	}
+
+	data, err := workerTemplate.ReadFile("worker-template/template_worker.go")
+	if err != nil {
+		return err
+	}
+
+	content := string(data)
+	content = strings.ReplaceAll(content, "TemplateWorker", ToUpperFirst(workerName)+"Worker")
+	content = strings.ReplaceAll(content, "templateWorker", ToLowerFirst(workerName)+"Worker")
+	content = strings.ReplaceAll(content, "TEMPLATE_SCHEDULE", *schedule)
+	content = strings.ReplaceAll(content, "github.com/nanda03dev/go-ms-template", packageName)
+
+	if err := WriteFileInPath(workerPath, content); err != nil {
+		return err
+	}
+
+	return ToUpdateSchedulerFile(schedulerPath, *name, *schedule, ToUpperFirst(workerName), *leader)
+}
+
+// validateSchedule parses schedule like the scheduler of the service does, so a typo fails here
+// rather than when the service starts
+func validateSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if _, err := time.ParseDuration(schedule); err == nil {
+		schedule = "@every " + schedule
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return nil
+}
+
+func ToUpdateSchedulerFile(filePath, name, schedule, workerName string, leader bool) error {
+	// Read the existing file content
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %v", filePath, err)
+	}
+
+	content := string(data)
+
+	newLine := fmt.Sprintf("{Name: %q, Schedule: %q, LeaderElection: %t, Handler: Run%sWorker},", name, schedule, leader, workerName)
+	startKeyword := "var ScheduledJobs = []ScheduledJob{"
+	endKeyword := "\n}"
+	content = AddNewLineToEnd(newLine, content, startKeyword, endKeyword, "\n\t", "")
+
+	return WriteFileInPath(filePath, content)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCacheBackend    = "memory"
+	defaultCacheTTL        = 5 * time.Minute
+	defaultCacheMaxEntries = 10000
+)
+
+// replaceCache fills the cache settings placeholder of the repository template from the
+// entity's "cache" block, an entity without one is not cached
+func replaceCache(content string, entity Entity) string {
+	if !strings.Contains(content, "TEMPLATE_CACHE_SETTINGS") {
+		return content
+	}
+
+	settings := "cache.Settings{}"
+	if cache := entity.Cache; cache != nil {
+		backend := strings.ToLower(strings.TrimSpace(cache.Backend))
+		switch backend {
+		case "":
+			backend = defaultCacheBackend
+		case "memory", "redis":
+		default:
+			fmt.Printf("Warning: %s has unknown cache backend %q, using %s\n", entity.EntityName, cache.Backend, defaultCacheBackend)
+			backend = defaultCacheBackend
+		}
+
+		ttl := defaultCacheTTL
+		if cache.TTL != "" {
+			parsed, err := time.ParseDuration(cache.TTL)
+			if err != nil || parsed <= 0 {
+				fmt.Printf("Warning: %s has invalid cache ttl %q, using %s\n", entity.EntityName, cache.TTL, defaultCacheTTL)
+			} else {
+				ttl = parsed
+			}
+		}
+
+		maxEntries := cache.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultCacheMaxEntries
+		}
+
+		settings = fmt.Sprintf("cache.Settings{Backend: cache.BACKEND_%s, TTL: %s, MaxEntries: %d, Filters: %t}",
+			strings.ToUpper(backend), goDuration(ttl), maxEntries, cache.Filters)
+	}
+
+	return strings.ReplaceAll(content, "TEMPLATE_CACHE_SETTINGS", settings)
+}
+
+// goDuration writes d as a Go expression, e.g. 90 * time.Second
+func goDuration(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, each := range units {
+		if d%each.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/each.unit, each.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
@@ -3,45 +3,79 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nanda03dev/go-ms-template/src/bootstrap"
+	"github.com/nanda03dev/go-ms-template/src/command"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/handler"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	bootstrap.Configure(cfg)
+
+	// Sub commands such as `replay` run and exit without starting the server
+	if handled, err := command.Execute(cfg, os.Args[1:]); handled {
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
-	fiberApp := fiber.New()
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	fiberApp := fiber.New(fiber.Config{
+		// Errors returned by handlers and middlewares are answered as RFC 7807 problems
+		ErrorHandler: handler.ErrorHandler,
+	})
 
-	applicationManager := bootstrap.NewApplicationManager(ctx, fiberApp)
+	applicationManager := bootstrap.NewApplicationManager(ctx, cfg, fiberApp)
 
 	applicationManager.ConnectDatabase()
 	applicationManager.Run()
 
-	// Handle graceful shutdown
+	// Start listening for HTTP requests
 	go func() {
-		// Listen for termination signals (Ctrl+C, kill)
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-		<-sigChan
+		slog.Info("starting server", "address", cfg.Server.Address, "env", cfg.Env)
+		if err := fiberApp.Listen(cfg.Server.Address); err != nil {
+			slog.Error("failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
 
-		// Graceful shutdown logic
-		log.Println("Shutting down worker...")
-		cancel() // Stop workers
+	// Listen for termination signals (Ctrl+C, kill)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
 
-		// Gracefully shut down the Fiber app
-		log.Println("Shutting down Fiber app...")
-		fiberApp.Shutdown()
-		applicationManager.DisconnectDatabase()
+	// A second signal skips the graceful shutdown
+	go func() {
+		<-sigChan
+		slog.Warn("received a second signal, exiting immediately")
+		os.Exit(1)
 	}()
 
-	// Start listening for HTTP requests
-	log.Println("Starting server on :3000")
-	if err := fiberApp.Listen(":3000"); err != nil {
-		log.Fatalf("Error starting server: %v", err)
-	}
+	applicationManager.Shutdown()
+	cancel()
 
+	// Flush the spans still buffered by the exporter
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
 }
@@ -0,0 +1,10 @@
+// Package migrations embeds the migrations tree into the service binary, so migrations run
+// whatever directory the binary is started from. See db.MigrationFiles.
+package migrations
+
+import "embed"
+
+// Files holds sql/ and seed/. Keep at least one file in each directory, the build fails otherwise.
+//
+//go:embed sql seed
+var Files embed.FS
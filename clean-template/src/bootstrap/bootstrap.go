@@ -2,11 +2,20 @@ package bootstrap
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/application/event_bus"
 	"github.com/nanda03dev/go-ms-template/src/core/application/worker"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/auth"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/cache"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
 	"github.com/nanda03dev/go-ms-template/src/core/interface/route"
 )
 
@@ -14,31 +23,92 @@ type ApplicationManager interface {
 	ConnectDatabase()
 	DisconnectDatabase()
 	Run()
+	Shutdown()
 }
 
 type applicationManager struct {
-	ctx      context.Context
-	fiberApp *fiber.App
+	ctx       context.Context
+	config    *config.Config
+	fiberApp  *fiber.App
+	databases *db.Databases
+	workers   *worker.Workers
 }
 
-func NewApplicationManager(ctx context.Context, fiberApp *fiber.App) ApplicationManager {
-	return &applicationManager{ctx: ctx, fiberApp: fiberApp}
+// Configure hands the configuration to the infrastructure packages. It runs before sub commands
+// and the server, as both use the logger and the database.
+func Configure(cfg *config.Config) {
+	slog.SetDefault(logger.New(cfg.Log, os.Stdout))
+	db.Configure(cfg.Database)
+	auth.Configure(cfg.Auth)
+	cache.Configure(cfg.Cache)
+	worker_channel.Initialize(cfg.Workers.ChannelSize)
+}
+
+func NewApplicationManager(ctx context.Context, cfg *config.Config, fiberApp *fiber.App) ApplicationManager {
+	return &applicationManager{ctx: ctx, config: cfg, fiberApp: fiberApp}
 }
 
 func (app *applicationManager) ConnectDatabase() {
-	db.ConnectAll()
+	app.databases = db.ConnectAll()
+
+	// Traffic is only routed to the service while its database answers
+	health.RegisterReadiness(health.NewChecker("sql", app.databases.SqlDB.Ping))
 }
 
 func (app *applicationManager) DisconnectDatabase() {
-	db.ConnectAll().DisconnectAll()
+	if app.databases == nil {
+		return
+	}
+	app.databases.DisconnectAll()
+	app.databases = nil
+	slog.Info("database connections closed")
 }
 
 func (app *applicationManager) Run() {
 
+	// Register in-process event subscribers before the workers dispatch events
+	event_bus.RegisterSubscribers()
+
 	// Initialize workers
-	log.Println("Starting worker...")
-	worker.InitializeWorkers(app.ctx)
+	slog.Info("starting workers")
+	app.workers = worker.InitializeWorkers(app.ctx, app.config.Workers)
 
 	// Initialize routes
-	route.InitializeRoutes(app.fiberApp)
+	route.InitializeRoutes(app.fiberApp, app.config.Server)
+}
+
+// Shutdown stops the service in order. Readiness fails first so load balancers stop routing traffic
+// here, then the HTTP server stops accepting requests and waits for the in-flight ones. The workers
+// handle the events already buffered, the events left when they run out of time are dead lettered,
+// and the database pools are closed last, once nothing uses them.
+func (app *applicationManager) Shutdown() {
+	settings := app.config.Shutdown
+	start := time.Now()
+
+	health.SetShuttingDown()
+	slog.Info("shutting down, readiness is failing", "drain_delay", settings.DrainDelay.String())
+	time.Sleep(settings.DrainDelay)
+
+	slog.Info("stopping HTTP server", "timeout", settings.HTTPTimeout.String())
+	if err := app.fiberApp.ShutdownWithTimeout(settings.HTTPTimeout); err != nil {
+		slog.Error("HTTP server did not stop in time, open connections were closed", "error", err)
+	} else {
+		slog.Info("HTTP server stopped")
+	}
+
+	if app.workers != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), settings.WorkerTimeout)
+		defer cancel()
+
+		slog.Info("stopping workers", "timeout", settings.WorkerTimeout.String())
+		if err := app.workers.Stop(ctx); err != nil {
+			slog.Error("workers did not stop in time", "error", err)
+		} else {
+			slog.Info("workers stopped")
+		}
+	}
+
+	app.DisconnectDatabase()
+	cache.Close()
+	slog.Info("shutdown complete", "duration_ms", time.Since(start).Milliseconds())
 }
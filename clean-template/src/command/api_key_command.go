@@ -0,0 +1,34 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/auth"
+	"github.com/nanda03dev/go-ms-template/src/helper"
+)
+
+// APIKey generates an API key and prints it with the hash to store in AUTH_API_KEYS_FILE.
+//
+//	./ms-name api-key -name=billing          generates a new key
+//	./ms-name api-key -key=<existing key>    hashes an existing key
+func APIKey(args []string) error {
+	flags := flag.NewFlagSet("api-key", flag.ExitOnError)
+	name := flags.String("name", "", "Name of the key owner, used as the principal subject")
+	key := flags.String("key", "", "Existing key to hash instead of generating one")
+	flags.Parse(args)
+
+	if *key == "" {
+		generated, err := helper.GenerateSecret(32)
+		if err != nil {
+			return err
+		}
+		*key = generated
+		fmt.Printf("key:  %s\n", *key)
+	}
+
+	hash := auth.HashAPIKey(*key)
+	fmt.Printf("hash: %s\n", hash)
+	fmt.Printf("entry: {\"name\": %q, \"hash\": %q, \"roles\": [], \"scopes\": []}\n", *name, hash)
+	return nil
+}
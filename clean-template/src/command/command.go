@@ -0,0 +1,31 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+)
+
+// Execute runs a sub command of the service binary, e.g. `./ms-name replay -entity=User`.
+// It reports false when args do not name a sub command and the server should start instead.
+func Execute(cfg *config.Config, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "replay":
+		return true, Replay(cfg, args[1:])
+	case "api-key":
+		return true, APIKey(args[1:])
+	case "tenant":
+		return true, Tenant(cfg, args[1:])
+	case "migrate":
+		return true, Migrate(cfg, args[1:])
+	default:
+		if len(args[0]) > 0 && args[0][0] != '-' {
+			return true, fmt.Errorf("unknown command %q", args[0])
+		}
+		return false, nil
+	}
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+)
+
+// Migrate applies and rolls back the migrations embedded in the binary, or those of
+// DB_MIGRATIONS_DIR. Runs of several replicas wait for each other, see db.Migrator.
+//
+//	./ms-name migrate up [-steps=N]     applies the pending migrations, then the seeds
+//	./ms-name migrate down [-steps=N]   rolls back the last migration, or the last N
+//	./ms-name migrate status            lists applied, pending, modified and missing migrations
+//	./ms-name migrate redo              rolls back and applies again the last migration
+//	./ms-name migrate create -name=add_user_email   writes an empty up and down script
+func Migrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down|status|redo|create")
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	steps := flags.Int("steps", 0, "Number of migrations to apply (up, default all) or roll back (down, default 1)")
+	name := flags.String("name", "", "snake_case name of the migration to create")
+	flags.Parse(args[1:])
+
+	if args[0] == "create" {
+		if *name == "" {
+			return fmt.Errorf("'-name' is required")
+		}
+		upPath, downPath, err := db.CreateMigration(filepath.Join(db.MigrationsDir(), db.SQL_DIR), *name)
+		if err != nil {
+			return err
+		}
+		log.Printf("Created %s and %s.\n", upPath, downPath)
+		return nil
+	}
+
+	files, err := db.MigrationFiles()
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.ConnectSqlDB(cfg.Database.URI)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Disconnect()
+
+	ctx := context.Background()
+	migrator := db.NewMigrator(sqlDB, files)
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx, *steps)
+	case "down":
+		if *steps == 0 {
+			*steps = 1
+		}
+		return migrator.Down(ctx, *steps)
+	case "redo":
+		return migrator.Redo(ctx)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "STATUS\tMIGRATION\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := ""
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", status.Status, status.Name, appliedAt)
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down, status, redo or create", args[0])
+	}
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+)
+
+// Replay rebuilds an entity table from the events table.
+//
+//	./ms-name replay -entity=User                 replays into a fresh <table>_replay table
+//	./ms-name replay -entity=User -table=users_v2 replays into a fresh users_v2 table
+//	./ms-name replay -entity=User -rebuild        empties and rebuilds the current table
+func Replay(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	entityName := flags.String("entity", "", "Entity to replay (e.g., User)")
+	table := flags.String("table", "", "Fresh table to replay into (default <table>_replay)")
+	rebuild := flags.Bool("rebuild", false, "Empty the entity's own table and rebuild it from events")
+	flags.Parse(args)
+
+	if *entityName == "" {
+		return fmt.Errorf("'-entity' is required")
+	}
+
+	if cfg.Database.TenantIsolation == db.TENANT_ISOLATION_SCHEMA {
+		return fmt.Errorf("replay is not supported with TENANT_ISOLATION=%s", db.TENANT_ISOLATION_SCHEMA)
+	}
+
+	projection, ok := repository.GetProjection(common.EntityName(*entityName))
+	if !ok {
+		return fmt.Errorf("unknown entity %s", *entityName)
+	}
+
+	currentTable, err := projection.TableName()
+	if err != nil {
+		return err
+	}
+
+	target := *table
+	if *rebuild {
+		if !projection.Config().EventSourced {
+			log.Printf("Warning: %s is not event sourced, events missing from the events table will be lost from %s\n", *entityName, currentTable)
+		}
+		target = currentTable
+	} else if target == "" {
+		target = currentTable + "_replay"
+	}
+
+	log.Printf("Replaying %s events into %s...\n", *entityName, target)
+	applied, err := projection.Replay(context.Background(), target)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Replayed %d %s events into %s.\n", applied, *entityName, target)
+	return nil
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+)
+
+// Tenant provisions a tenant. With row isolation (the default) tenants need no provisioning.
+//
+//	./ms-name tenant -create=acme    creates schema tenant_acme and migrates the tenant scoped tables into it
+func Tenant(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("tenant", flag.ExitOnError)
+	create := flags.String("create", "", "Tenant to create the schema of")
+	flags.Parse(args)
+
+	if *create == "" {
+		return fmt.Errorf("'-create' is required")
+	}
+
+	if cfg.Database.TenantIsolation != db.TENANT_ISOLATION_SCHEMA {
+		return fmt.Errorf("tenants only need to be created with TENANT_ISOLATION=%s", db.TENANT_ISOLATION_SCHEMA)
+	}
+
+	databases := db.ConnectAll()
+	defer databases.DisconnectAll()
+
+	if err := databases.SqlDB.CreateTenantSchema(*create); err != nil {
+		return err
+	}
+
+	log.Printf("Created schema %s for tenant %s.\n", db.TenantSchema(*create), *create)
+	return nil
+}
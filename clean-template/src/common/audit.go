@@ -0,0 +1,28 @@
+package common
+
+import "context"
+
+// AuditedModel is implemented by the models of entities with audit fields. Their created_by,
+// updated_by and deleted_by columns are filled with the actor of the statement's context.
+type AuditedModel interface {
+	Audited()
+}
+
+type actorContextKey struct{}
+
+// WithActor sets who writes with ctx when it carries no principal, e.g. the original actor of replayed events.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor or else the subject of the principal,
+// empty for system writes.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok {
+		return actor
+	}
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+	return ""
+}
@@ -16,7 +16,8 @@ const (
 )
 
 const (
-	ENTITY_CREATED EventType = "ENTITY_CREATED"
-	ENTITY_UPDATED EventType = "ENTITY_UPDATED"
-	ENTITY_DELETED EventType = "ENTITY_DELETED"
+	ENTITY_CREATED  EventType = "ENTITY_CREATED"
+	ENTITY_UPDATED  EventType = "ENTITY_UPDATED"
+	ENTITY_DELETED  EventType = "ENTITY_DELETED"
+	ENTITY_RESTORED EventType = "ENTITY_RESTORED"
 )
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"context"
+	"slices"
+)
+
+type AuthMethod string
+
+const (
+	AUTH_METHOD_JWT     AuthMethod = "jwt"
+	AUTH_METHOD_API_KEY AuthMethod = "api_key"
+	AUTH_METHOD_NONE    AuthMethod = "none"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  AuthMethod
+	// TenantID the credentials are bound to, empty when they may act for any tenant
+	TenantID string
+	Roles    []string
+	Scopes   []string
+	Claims   map[string]any
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller stored by the authentication middleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,16 @@
+package common
+
+import "context"
+
+type requestIdContextKey struct{}
+
+func WithRequestID(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIDFromContext returns the id of the request being served, or of the request that
+// produced the event a worker is handling.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdContextKey{}).(string)
+	return requestId, ok && requestId != ""
+}
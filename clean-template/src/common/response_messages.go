@@ -2,9 +2,12 @@ package common
 
 const (
 	InvalidRequestError     = "Invalid request"
-	ErrorDeletingData       = "Error while deleting data"
 	DataDeletedSuccessfully = "Data deleted successfully"
+	InvalidAsOfError        = "Invalid as_of, expected an RFC3339 time"
+	ForbiddenError          = "You are not allowed to perform this operation"
 
-	//TemplateEntity
-	TemplateEntityNotFoundError = "templateEntity not found"
+	// Repository
+	RecordNotFoundError   = "record not found"
+	RecordExistsError     = "record already exists"
+	RecordReferencedError = "record references a missing record or is still referenced"
 )
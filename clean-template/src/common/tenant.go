@@ -0,0 +1,40 @@
+package common
+
+import "context"
+
+// TenantScopedModel is implemented by the models of multi tenant entities. Every statement on
+// them is scoped to the tenant of its context and fails when the context has no tenant.
+type TenantScopedModel interface {
+	TenantScoped()
+}
+
+// TenantAwareModel is implemented by shared models holding a tenant, like events. Statements on
+// them are scoped only when the context has a tenant.
+type TenantAwareModel interface {
+	TenantAware()
+}
+
+type tenantContextKey struct{}
+
+type allTenantsContextKey struct{}
+
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantId)
+}
+
+// TenantFromContext returns the tenant resolved for a request or set by a worker.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantId, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantId, ok && tenantId != ""
+}
+
+// WithAllTenants lifts tenant scoping for system operations like replays and maintenance jobs.
+// Never use it on a request context.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsContextKey{}, true)
+}
+
+func IsAllTenants(ctx context.Context) bool {
+	allTenants, _ := ctx.Value(allTenantsContextKey{}).(bool)
+	return allTenants
+}
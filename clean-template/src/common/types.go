@@ -1,5 +1,7 @@
 package common
 
+import "time"
+
 type ConditionOperation string
 
 type Condition struct {
@@ -21,6 +23,8 @@ type FilterQuery struct {
 	MaxResults uint        `json:"maxResults"`
 	Offset     uint        `json:"offset"`
 	Logic      string      `json:"logic"`
+	// Restrictions are ANDed with the conditions whatever the logic, set by the service layer only
+	Restrictions []Condition `json:"-"`
 }
 
 type EventType string
@@ -29,12 +33,23 @@ type EntityName string
 
 type EntityConfig struct {
 	EventStore bool
+	// EventSourced entities store their events in the same transaction as the write,
+	// making the events table the source of truth the entity table is projected from
+	EventSourced bool
 }
 
 type Event struct {
 	ID         string
 	EntityId   string
 	EntityName EntityName
+	TenantID   string // Tenant of the record, empty for entities that are not multi tenant
+	Actor      string // Who made the change, empty for system changes
+	RequestID  string // Request that made the change, only kept in memory to correlate worker logs
 	Type       EventType
+	OccurredAt time.Time
+	Data       string // JSON snapshot of the aggregate after the operation
 	Config     EntityConfig
+
+	// W3C trace context of the change, only kept in memory so workers continue the request's trace
+	TraceContext map[string]string
 }
@@ -0,0 +1,18 @@
+package authorization
+
+// ROLE_ADMIN is held by the operators of the service, e.g. a JWT with "roles": ["admin"] or an
+// API key with the admin role or scope
+const ROLE_ADMIN = "admin"
+
+// AdminPolicy guards the APIs that span every entity: the event store, whose events carry full
+// record snapshots, the webhook subscriptions and deliveries, and the dead letters. Only admins
+// may use them.
+var AdminPolicy = Policy{
+	Rules: map[Operation]Rule{
+		OPERATION_CREATE: {Roles: []string{ROLE_ADMIN}, Scopes: []string{ROLE_ADMIN}},
+		OPERATION_READ:   {Roles: []string{ROLE_ADMIN}, Scopes: []string{ROLE_ADMIN}},
+		OPERATION_LIST:   {Roles: []string{ROLE_ADMIN}, Scopes: []string{ROLE_ADMIN}},
+		OPERATION_UPDATE: {Roles: []string{ROLE_ADMIN}, Scopes: []string{ROLE_ADMIN}},
+		OPERATION_DELETE: {Roles: []string{ROLE_ADMIN}, Scopes: []string{ROLE_ADMIN}},
+	},
+}
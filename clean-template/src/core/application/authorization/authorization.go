@@ -0,0 +1,140 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+)
+
+type Operation string
+
+const (
+	OPERATION_CREATE Operation = "create"
+	OPERATION_READ   Operation = "read"
+	OPERATION_LIST   Operation = "list"
+	OPERATION_UPDATE Operation = "update"
+	OPERATION_DELETE Operation = "delete"
+)
+
+// Rule grants an operation to principals with any of Roles or Scopes. With Owner any
+// other authenticated principal is granted the operation on the records it owns.
+type Rule struct {
+	Roles  []string
+	Scopes []string
+	Owner  bool
+}
+
+// Policy holds the rules of one entity, generated from its "permissions" block in gStructify.config.json.
+// Operations without a rule are open to every authenticated principal. A record is owned by the
+// principal whose subject equals the record's OwnerField (OwnerColumn in the database).
+type Policy struct {
+	OwnerField  string
+	OwnerColumn string
+	Rules       map[Operation]Rule
+}
+
+type Access int
+
+const (
+	ACCESS_DENIED Access = iota
+	ACCESS_OWNED         // only records owned by the principal
+	ACCESS_ALL
+)
+
+// Access decides how much of an operation the principal in ctx may perform.
+func (p Policy) Access(ctx context.Context, operation Operation) Access {
+	rule, ok := p.Rules[operation]
+	if !ok {
+		return ACCESS_ALL
+	}
+
+	principal, ok := common.PrincipalFromContext(ctx)
+	if !ok {
+		return ACCESS_DENIED
+	}
+
+	// Authentication is disabled, see AUTH_DISABLED
+	if principal.Method == common.AUTH_METHOD_NONE {
+		return ACCESS_ALL
+	}
+
+	for _, role := range rule.Roles {
+		if principal.HasRole(role) {
+			return ACCESS_ALL
+		}
+	}
+	for _, scope := range rule.Scopes {
+		if principal.HasScope(scope) {
+			return ACCESS_ALL
+		}
+	}
+
+	if rule.Owner && p.OwnerField != "" && principal.Subject != "" {
+		return ACCESS_OWNED
+	}
+
+	return ACCESS_DENIED
+}
+
+// Authorize checks an operation on a record; pass a nil record to require access to all records.
+func (p Policy) Authorize(ctx context.Context, operation Operation, record any) error {
+	switch p.Access(ctx, operation) {
+	case ACCESS_ALL:
+		return nil
+	case ACCESS_OWNED:
+		if record != nil && p.IsOwner(ctx, record) {
+			return nil
+		}
+	}
+	return apperror.Forbidden(common.ForbiddenError).Wrap(fmt.Errorf("%s not permitted", operation))
+}
+
+// Restrict limits a list query to the records the principal in ctx may see.
+func (p Policy) Restrict(ctx context.Context, filterQuery common.FilterQuery) (common.FilterQuery, error) {
+	switch p.Access(ctx, OPERATION_LIST) {
+	case ACCESS_ALL:
+		return filterQuery, nil
+	case ACCESS_OWNED:
+		principal, _ := common.PrincipalFromContext(ctx)
+		filterQuery.Restrictions = append(slices.Clone(filterQuery.Restrictions), common.Condition{
+			Key:      p.OwnerColumn,
+			Value:    principal.Subject,
+			Operator: common.CONDITION_EQ,
+		})
+		return filterQuery, nil
+	default:
+		return filterQuery, apperror.Forbidden(common.ForbiddenError).Wrap(fmt.Errorf("%s not permitted", OPERATION_LIST))
+	}
+}
+
+func (p Policy) IsOwner(ctx context.Context, record any) bool {
+	principal, ok := common.PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" {
+		return false
+	}
+	owner, ok := p.OwnerOf(record)
+	return ok && owner == principal.Subject
+}
+
+// OwnerOf reads OwnerField of a record, formatted as a string.
+func (p Policy) OwnerOf(record any) (string, bool) {
+	if p.OwnerField == "" {
+		return "", false
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(record))
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	field := value.FieldByName(p.OwnerField)
+	if !field.IsValid() {
+		return "", false
+	}
+
+	return fmt.Sprint(field.Interface()), true
+}
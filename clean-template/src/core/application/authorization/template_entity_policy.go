@@ -0,0 +1,10 @@
+package authorization
+
+// TemplateEntityPolicy is generated from the "permissions" block of templateEntity in gStructify.config.json
+var TemplateEntityPolicy = Policy{
+	OwnerField:  "TEMPLATE_OWNER_FIELD",
+	OwnerColumn: "TEMPLATE_OWNER_COLUMN",
+	Rules: map[Operation]Rule{
+		TEMPLATE_POLICY_RULES
+	},
+}
@@ -0,0 +1,59 @@
+package event_bus
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+)
+
+// Subscription is one in-process handler of an entity event type
+type Subscription struct {
+	Name       string
+	EntityName common.EntityName
+	EventType  common.EventType
+	Handle     func(ctx context.Context, event common.Event) error
+}
+
+var (
+	subscriptionsMutex sync.RWMutex
+	subscriptions      []Subscription
+)
+
+// Subscribe registers a handler for an entity event type. Prefer the typed helpers,
+// e.g. OnOrderCreated, which decode the event snapshot into the aggregate.
+func Subscribe(entityName common.EntityName, eventType common.EventType, name string, handle func(ctx context.Context, event common.Event) error) {
+	subscriptionsMutex.Lock()
+	defer subscriptionsMutex.Unlock()
+
+	subscriptions = append(subscriptions, Subscription{
+		Name:       name,
+		EntityName: entityName,
+		EventType:  eventType,
+		Handle:     handle,
+	})
+}
+
+// SubscriptionsFor returns the handlers interested in the event
+func SubscriptionsFor(event common.Event) []Subscription {
+	subscriptionsMutex.RLock()
+	defer subscriptionsMutex.RUnlock()
+
+	var result []Subscription
+	for _, subscription := range subscriptions {
+		if subscription.EntityName == event.EntityName && subscription.EventType == event.Type {
+			result = append(result, subscription)
+		}
+	}
+	return result
+}
+
+// handlerName names a handler function for logs, e.g. "my-ms/src/core/application/event_bus.sendWelcomeEmail"
+func handlerName(handler any) string {
+	if function := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); function != nil {
+		return function.Name()
+	}
+	return "anonymous"
+}
@@ -0,0 +1,13 @@
+package event_bus
+
+// RegisterSubscribers is called once at startup, before the workers start.
+// Register in-process handlers for entity events here, e.g.
+//
+//	OnUserCreated(func(ctx context.Context, event UserEvent) error {
+//		return mailer.SendWelcome(ctx, event.User.Email)
+//	})
+//
+// Handlers run asynchronously in the domain event worker. A failing or panicking handler
+// is retried on its own with backoff and finally dead lettered without affecting others.
+func RegisterSubscribers() {
+}
@@ -0,0 +1,45 @@
+package event_bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+)
+
+// TemplateEntityEvent is delivered to templateEntity subscribers with the state after the operation
+type TemplateEntityEvent struct {
+	common.Event
+	TemplateEntity *aggregate.TemplateEntity
+}
+
+type TemplateEntityEventHandler func(ctx context.Context, event TemplateEntityEvent) error
+
+func OnTemplateEntityCreated(handler TemplateEntityEventHandler) {
+	subscribeTemplateEntity(common.ENTITY_CREATED, handler)
+}
+
+func OnTemplateEntityUpdated(handler TemplateEntityEventHandler) {
+	subscribeTemplateEntity(common.ENTITY_UPDATED, handler)
+}
+
+func OnTemplateEntityDeleted(handler TemplateEntityEventHandler) {
+	subscribeTemplateEntity(common.ENTITY_DELETED, handler)
+}
+
+func OnTemplateEntityRestored(handler TemplateEntityEventHandler) {
+	subscribeTemplateEntity(common.ENTITY_RESTORED, handler)
+}
+
+func subscribeTemplateEntity(eventType common.EventType, handler TemplateEntityEventHandler) {
+	Subscribe(entity.TemplateEntityEntityName, eventType, handlerName(handler), func(ctx context.Context, event common.Event) error {
+		var templateEntity aggregate.TemplateEntity
+		if err := json.Unmarshal([]byte(event.Data), &templateEntity); err != nil {
+			return fmt.Errorf("failed to decode templateEntity snapshot of event %s: %w", event.ID, err)
+		}
+		return handler(ctx, TemplateEntityEvent{Event: event, TemplateEntity: &templateEntity})
+	})
+}
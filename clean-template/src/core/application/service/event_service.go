@@ -1,47 +1,136 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/nanda03dev/go-ms-template/src/common"
 	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
 )
 
 type EventService interface {
-	Create(createDTO common.Event) (*aggregate.Event, error)
-	GetById(id string) (*aggregate.Event, error)
-	FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.Event, error)
-	Update(id string, updateDTO common.Event) (*aggregate.Event, error)
-	Delete(id string) error
+	Create(ctx context.Context, createDTO common.Event) (*aggregate.Event, error)
+	CreateBatch(ctx context.Context, createDTOs []common.Event) ([]*aggregate.Event, error)
+	GetById(ctx context.Context, id string) (*aggregate.Event, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.Event, error)
+	FindByEntity(ctx context.Context, entityName common.EntityName, entityId string) ([]*aggregate.Event, error)
+	Update(ctx context.Context, id string, updateDTO common.Event) (*aggregate.Event, error)
+	Delete(ctx context.Context, id string) error
+	DeadLetter(ctx context.Context, event common.Event, channel string, reason error, attempts int) error
+	FindDeadLetters(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.DeadLetterEvent, error)
+	ReplayDeadLetter(ctx context.Context, id string) error
+	DeleteDeadLetter(ctx context.Context, id string) error
+	RequeueParkedEvents(ctx context.Context) (int, error)
 }
 
 type eventService struct {
-	eventRepo repository.EventRepository
+	eventRepo           repository.EventRepository
+	deadLetterEventRepo repository.DeadLetterEventRepository
 }
 
-func NewEventService(eventRepository repository.EventRepository) EventService {
+func NewEventService(eventRepository repository.EventRepository, deadLetterEventRepository repository.DeadLetterEventRepository) EventService {
 	return &eventService{
-		eventRepo: eventRepository,
+		eventRepo:           eventRepository,
+		deadLetterEventRepo: deadLetterEventRepository,
 	}
 }
 
-func (s *eventService) Create(createDTO common.Event) (*aggregate.Event, error) {
+func (s *eventService) Create(ctx context.Context, createDTO common.Event) (*aggregate.Event, error) {
 	newData := aggregate.NewEvent(createDTO)
-	return s.eventRepo.Create(newData)
+	return s.eventRepo.Create(ctx, newData)
+}
+
+func (s *eventService) CreateBatch(ctx context.Context, createDTOs []common.Event) ([]*aggregate.Event, error) {
+	var newData = make([]*aggregate.Event, 0, len(createDTOs))
+	for _, createDTO := range createDTOs {
+		newData = append(newData, aggregate.NewEvent(createDTO))
+	}
+	return s.eventRepo.BulkCreate(ctx, newData)
 }
 
-func (s *eventService) GetById(id string) (*aggregate.Event, error) {
-	return s.eventRepo.FindById(id)
+func (s *eventService) GetById(ctx context.Context, id string) (*aggregate.Event, error) {
+	return s.eventRepo.FindById(ctx, id)
 }
 
-func (s *eventService) FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.Event, error) {
-	return s.eventRepo.FindWithFilter(filterQuery)
+func (s *eventService) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.Event, error) {
+	return s.eventRepo.FindWithFilter(ctx, filterQuery)
 }
 
-func (s *eventService) Update(id string, updateDTO common.Event) (*aggregate.Event, error) {
+func (s *eventService) FindByEntity(ctx context.Context, entityName common.EntityName, entityId string) ([]*aggregate.Event, error) {
+	return s.eventRepo.FindByEntity(ctx, entityName, entityId)
+}
+
+func (s *eventService) Update(ctx context.Context, id string, updateDTO common.Event) (*aggregate.Event, error) {
 	updatedData := aggregate.UpdateEvent(id, updateDTO)
-	return s.eventRepo.Update(updatedData)
+	return s.eventRepo.Update(ctx, updatedData)
+}
+
+func (s *eventService) Delete(ctx context.Context, id string) error {
+	return s.eventRepo.Delete(ctx, id)
+}
+
+// DeadLetter parks an event taken from channel that could not be handled, so it can be inspected
+// and replayed later
+func (s *eventService) DeadLetter(ctx context.Context, event common.Event, channel string, reason error, attempts int) error {
+	_, err := s.deadLetterEventRepo.Create(ctx, aggregate.NewDeadLetterEvent(event, channel, reason, attempts))
+	return err
+}
+
+func (s *eventService) FindDeadLetters(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.DeadLetterEvent, error) {
+	return s.deadLetterEventRepo.FindWithFilter(ctx, filterQuery)
+}
+
+// ReplayDeadLetter pushes the parked event back onto the channel it was taken from and removes the
+// dead letter. A replayed domain event runs every subscriber of the event again.
+func (s *eventService) ReplayDeadLetter(ctx context.Context, id string) error {
+	deadLetter, err := s.deadLetterEventRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.replay(ctx, deadLetter)
+}
+
+// RequeueParkedEvents replays the events parked at shutdown, which no worker attempted, in the order
+// they were parked. It stops at the first channel that is full; the remaining events stay parked.
+// Dead letters recorded without their event cannot be replayed and are skipped.
+func (s *eventService) RequeueParkedEvents(ctx context.Context) (int, error) {
+	parked, err := s.deadLetterEventRepo.FindWithFilter(ctx, common.FilterQuery{
+		Conditions: []common.Condition{{Key: "attempts", Value: "0", Operator: common.CONDITION_EQ}},
+		Sorts:      []common.Sort{{Key: "created_at", Type: common.SORT_ASC}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, deadLetter := range parked {
+		err := s.replay(ctx, deadLetter)
+		if errors.Is(err, apperror.ErrValidation) {
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+func (s *eventService) replay(ctx context.Context, deadLetter *aggregate.DeadLetterEvent) error {
+	event, ok := deadLetter.Event()
+	if !ok {
+		return apperror.Validation("dead letter %s was recorded without its event and cannot be replayed", deadLetter.ID)
+	}
+	if !worker_channel.Push(deadLetter.Channel, event) {
+		return apperror.Conflict("the %s channel is full or unknown, retry later", deadLetter.Channel)
+	}
+
+	return s.deadLetterEventRepo.Delete(ctx, deadLetter.ID)
 }
 
-func (s *eventService) Delete(id string) error {
-	return s.eventRepo.Delete(id)
+func (s *eventService) DeleteDeadLetter(ctx context.Context, id string) error {
+	return s.deadLetterEventRepo.Delete(ctx, id)
 }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+)
+
+// fakeDeadLetterRepository keeps dead letters in the order they were parked and filters them on
+// equality conditions
+type fakeDeadLetterRepository struct {
+	repository.DeadLetterEventRepository
+	deadLetters []*aggregate.DeadLetterEvent
+}
+
+func (r *fakeDeadLetterRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.DeadLetterEvent, error) {
+	var result []*aggregate.DeadLetterEvent
+	for _, deadLetter := range r.deadLetters {
+		values := map[string]string{"attempts": strconv.Itoa(deadLetter.Attempts), "channel": deadLetter.Channel}
+		matches := true
+		for _, condition := range filterQuery.Conditions {
+			matches = matches && values[condition.Key] == condition.Value
+		}
+		if matches {
+			result = append(result, deadLetter)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeDeadLetterRepository) Delete(ctx context.Context, id string) error {
+	for index, deadLetter := range r.deadLetters {
+		if deadLetter.ID == id {
+			r.deadLetters = append(r.deadLetters[:index], r.deadLetters[index+1:]...)
+			return nil
+		}
+	}
+	return apperror.NotFound("dead letter %s not found", id)
+}
+
+func TestRequeueParkedEvents(t *testing.T) {
+	// Room for two events, the third parked event does not fit
+	worker_channel.Initialize(2)
+
+	shutdown := fmt.Errorf("shutdown before the %s channel was drained", worker_channel.CHANNEL_CRUD)
+	park := func(id string, attempts int) *aggregate.DeadLetterEvent {
+		deadLetter := aggregate.NewDeadLetterEvent(common.Event{ID: id}, worker_channel.CHANNEL_CRUD, shutdown, attempts)
+		deadLetter.ID = "dead-letter-" + id
+		return deadLetter
+	}
+	withoutEvent := park("event-0", 0)
+	withoutEvent.Payload = ""
+	deadLetters := &fakeDeadLetterRepository{deadLetters: []*aggregate.DeadLetterEvent{
+		withoutEvent,
+		park("event-1", 0),
+		park("failed", 5),
+		park("event-2", 0),
+		park("event-3", 0),
+	}}
+	service := NewEventService(nil, deadLetters)
+
+	requeued, err := service.RequeueParkedEvents(context.Background())
+	if !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("RequeueParkedEvents returned %v, want a conflict once the channel is full", err)
+	}
+	if requeued != 2 {
+		t.Errorf("requeued = %d, want 2", requeued)
+	}
+
+	channel := worker_channel.GetCRUDEventChannel()
+	for _, want := range []string{"event-1", "event-2"} {
+		if event := <-channel; event.ID != want {
+			t.Errorf("requeued %s, want %s", event.ID, want)
+		}
+	}
+
+	var remaining []string
+	for _, deadLetter := range deadLetters.deadLetters {
+		remaining = append(remaining, deadLetter.EventId)
+	}
+	// The dead letter without its event, the failed event and the one left over stay parked
+	if fmt.Sprint(remaining) != "[event-0 failed event-3]" {
+		t.Errorf("remaining dead letters = %v, want [event-0 failed event-3]", remaining)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"sync"
 
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/webhook"
 )
 
 type Services struct {
 	TemplateEntityService TemplateEntityService
 	EventService          EventService
+	WebhookService        WebhookService
 }
 
 var (
@@ -21,7 +23,8 @@ func GetServices() *Services {
 		var AllRepository = repository.GetRepositories()
 		allServices = &Services{
 			TemplateEntityService: NewTemplateEntityService(AllRepository.TemplateEntityRepository),
-			EventService:          NewEventService(AllRepository.EventRepository),
+			EventService:          NewEventService(AllRepository.EventRepository, AllRepository.DeadLetterEventRepository),
+			WebhookService:        NewWebhookService(AllRepository.WebhookSubscriptionRepository, AllRepository.WebhookDeliveryRepository, webhook.NewSender(nil)),
 		}
 	})
 	return allServices
@@ -1,47 +1,156 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/authorization"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
 	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
 )
 
+// TemplateEntityService checks every operation against authorization.TemplateEntityPolicy
+// for the principal carried by ctx.
 type TemplateEntityService interface {
-	Create(createDTO dto.CreateTemplateEntityDTO) (*aggregate.TemplateEntity, error)
-	GetById(id string) (*aggregate.TemplateEntity, error)
-	FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error)
-	Update(id string, updateDTO dto.UpdateTemplateEntityDTO) (*aggregate.TemplateEntity, error)
-	Delete(id string) error
+	Create(ctx context.Context, createDTO dto.CreateTemplateEntityDTO) (*aggregate.TemplateEntity, error)
+	GetById(ctx context.Context, id string) (*aggregate.TemplateEntity, error)
+	GetByIdAsOf(ctx context.Context, id string, at time.Time) (*aggregate.TemplateEntity, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error)
+	Update(ctx context.Context, id string, updateDTO dto.UpdateTemplateEntityDTO) (*aggregate.TemplateEntity, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*aggregate.TemplateEntity, error)
+	GetHistory(ctx context.Context, id string) ([]*aggregate.Event, error)
 }
 
 type templateEntityService struct {
 	templateEntityRepo repository.TemplateEntityRepository
+	policy             authorization.Policy
 }
 
 func NewTemplateEntityService(templateEntityRepo repository.TemplateEntityRepository) TemplateEntityService {
 	return &templateEntityService{
 		templateEntityRepo: templateEntityRepo,
+		policy:             authorization.TemplateEntityPolicy,
 	}
 }
 
-func (s *templateEntityService) Create(createDTO dto.CreateTemplateEntityDTO) (*aggregate.TemplateEntity, error) {
+func (s *templateEntityService) Create(ctx context.Context, createDTO dto.CreateTemplateEntityDTO) (_ *aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.Create")
+	defer func() { tracing.End(span, err) }()
+
 	newData := aggregate.NewTemplateEntity(createDTO)
-	return s.templateEntityRepo.Create(newData)
+	if err := s.policy.Authorize(ctx, authorization.OPERATION_CREATE, newData); err != nil {
+		return nil, err
+	}
+	return s.templateEntityRepo.Create(ctx, newData)
+}
+
+func (s *templateEntityService) GetById(ctx context.Context, id string) (_ *aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.GetById")
+	defer func() { tracing.End(span, err) }()
+
+	templateEntity, err := s.templateEntityRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(ctx, authorization.OPERATION_READ, templateEntity); err != nil {
+		return nil, err
+	}
+	return templateEntity, nil
 }
 
-func (s *templateEntityService) GetById(id string) (*aggregate.TemplateEntity, error) {
-	return s.templateEntityRepo.FindById(id)
+func (s *templateEntityService) GetByIdAsOf(ctx context.Context, id string, at time.Time) (_ *aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.GetByIdAsOf")
+	defer func() { tracing.End(span, err) }()
+
+	// Events of multi tenant entities are only narrowed to the tenant of the request
+	if err := db.RequireTenant(ctx, &entity.TemplateEntity{}); err != nil {
+		return nil, err
+	}
+	templateEntity, err := s.templateEntityRepo.AsOf(ctx, id, at)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(ctx, authorization.OPERATION_READ, templateEntity); err != nil {
+		return nil, err
+	}
+	return templateEntity, nil
 }
 
-func (s *templateEntityService) FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error) {
-	return s.templateEntityRepo.FindWithFilter(filterQuery)
+func (s *templateEntityService) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) (_ []*aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.FindWithFilter")
+	defer func() { tracing.End(span, err) }()
+
+	filterQuery, err = s.policy.Restrict(ctx, filterQuery)
+	if err != nil {
+		return nil, err
+	}
+	return s.templateEntityRepo.FindWithFilter(ctx, filterQuery)
 }
 
-func (s *templateEntityService) Update(id string, updateDTO dto.UpdateTemplateEntityDTO) (*aggregate.TemplateEntity, error) {
+func (s *templateEntityService) Update(ctx context.Context, id string, updateDTO dto.UpdateTemplateEntityDTO) (_ *aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	// Authorized against the primary, a lagging replica may hold a previous owner
+	existing, err := s.templateEntityRepo.FindById(db.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
 	updatedData := aggregate.UpdateTemplateEntity(id, updateDTO)
-	return s.templateEntityRepo.Update(updatedData)
+
+	// An owner may neither update another's record nor hand its own record over
+	for _, record := range []*aggregate.TemplateEntity{existing, updatedData} {
+		if err := s.policy.Authorize(ctx, authorization.OPERATION_UPDATE, record); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.templateEntityRepo.Update(ctx, updatedData)
+}
+
+func (s *templateEntityService) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := s.templateEntityRepo.FindById(db.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Authorize(ctx, authorization.OPERATION_DELETE, existing); err != nil {
+		return err
+	}
+	return s.templateEntityRepo.Delete(ctx, id)
+}
+
+// Restore needs delete access to all records, owners cannot restore their own deleted records
+func (s *templateEntityService) Restore(ctx context.Context, id string) (_ *aggregate.TemplateEntity, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.Restore")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.policy.Authorize(ctx, authorization.OPERATION_DELETE, nil); err != nil {
+		return nil, err
+	}
+	return s.templateEntityRepo.Restore(ctx, id)
 }
 
-func (s *templateEntityService) Delete(id string) error {
-	return s.templateEntityRepo.Delete(id)
+func (s *templateEntityService) GetHistory(ctx context.Context, id string) (_ []*aggregate.Event, err error) {
+	ctx, span := tracing.Start(ctx, "TemplateEntityService.GetHistory")
+	defer func() { tracing.End(span, err) }()
+
+	if err := db.RequireTenant(ctx, &entity.TemplateEntity{}); err != nil {
+		return nil, err
+	}
+	if s.policy.Access(ctx, authorization.OPERATION_READ) != authorization.ACCESS_ALL {
+		// Owners can read the history of their current records only
+		if _, err := s.GetById(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return s.templateEntityRepo.FindHistory(ctx, id)
 }
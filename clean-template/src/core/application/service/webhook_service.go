@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/webhook"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, createDTO dto.CreateWebhookSubscriptionDTO) (*aggregate.WebhookSubscription, error)
+	GetById(ctx context.Context, id string) (*aggregate.WebhookSubscription, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookSubscription, error)
+	Update(ctx context.Context, id string, updateDTO dto.UpdateWebhookSubscriptionDTO) (*aggregate.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+	FindSubscriptionsForEvent(ctx context.Context, event common.Event) ([]*aggregate.WebhookSubscription, error)
+	CreateDelivery(ctx context.Context, subscription *aggregate.WebhookSubscription, event common.Event) (*aggregate.WebhookDelivery, error)
+	Send(ctx context.Context, delivery *aggregate.WebhookDelivery) error
+	FindDeliveries(ctx context.Context, subscriptionId string, filterQuery common.FilterQuery) ([]*aggregate.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryId string) (*aggregate.WebhookDelivery, error)
+	ScheduleRetry(ctx context.Context, delivery *aggregate.WebhookDelivery, at time.Time) error
+	FindDueDeliveries(ctx context.Context, limit int) ([]*aggregate.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhookSubscriptionRepo repository.WebhookSubscriptionRepository
+	webhookDeliveryRepo     repository.WebhookDeliveryRepository
+	sender                  *webhook.Sender
+}
+
+func NewWebhookService(webhookSubscriptionRepo repository.WebhookSubscriptionRepository, webhookDeliveryRepo repository.WebhookDeliveryRepository, sender *webhook.Sender) WebhookService {
+	return &webhookService{
+		webhookSubscriptionRepo: webhookSubscriptionRepo,
+		webhookDeliveryRepo:     webhookDeliveryRepo,
+		sender:                  sender,
+	}
+}
+
+func (s *webhookService) Create(ctx context.Context, createDTO dto.CreateWebhookSubscriptionDTO) (*aggregate.WebhookSubscription, error) {
+	newData, err := aggregate.NewWebhookSubscription(createDTO)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookSubscriptionRepo.Create(ctx, newData)
+}
+
+func (s *webhookService) GetById(ctx context.Context, id string) (*aggregate.WebhookSubscription, error) {
+	return s.webhookSubscriptionRepo.FindById(ctx, id)
+}
+
+func (s *webhookService) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookSubscription, error) {
+	return s.webhookSubscriptionRepo.FindWithFilter(ctx, filterQuery)
+}
+
+func (s *webhookService) Update(ctx context.Context, id string, updateDTO dto.UpdateWebhookSubscriptionDTO) (*aggregate.WebhookSubscription, error) {
+	existing, err := s.webhookSubscriptionRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedData, err := aggregate.UpdateWebhookSubscription(existing, updateDTO)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookSubscriptionRepo.Update(ctx, updatedData)
+}
+
+func (s *webhookService) Delete(ctx context.Context, id string) error {
+	return s.webhookSubscriptionRepo.Delete(ctx, id)
+}
+
+func (s *webhookService) FindSubscriptionsForEvent(ctx context.Context, event common.Event) ([]*aggregate.WebhookSubscription, error) {
+	subscriptions, err := s.webhookSubscriptionRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*aggregate.WebhookSubscription
+	for _, subscription := range subscriptions {
+		if subscription.Matches(event) {
+			result = append(result, subscription)
+		}
+	}
+	return result, nil
+}
+
+// CreateDelivery stores a pending delivery log holding the payload sent for the event
+func (s *webhookService) CreateDelivery(ctx context.Context, subscription *aggregate.WebhookSubscription, event common.Event) (*aggregate.WebhookDelivery, error) {
+	payload, err := json.Marshal(dto.WebhookEventPayload{
+		ID:         event.ID,
+		EntityId:   event.EntityId,
+		EntityName: string(event.EntityName),
+		TenantId:   event.TenantID,
+		Actor:      event.Actor,
+		Type:       string(event.Type),
+		OccurredAt: event.OccurredAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	return s.webhookDeliveryRepo.Create(ctx, aggregate.NewWebhookDelivery(subscription.ID, event, string(payload)))
+}
+
+// Send makes one delivery attempt and records its outcome in the delivery log
+func (s *webhookService) Send(ctx context.Context, delivery *aggregate.WebhookDelivery) error {
+	subscription, err := s.webhookSubscriptionRepo.FindById(ctx, delivery.SubscriptionId)
+	if err != nil {
+		return fmt.Errorf("webhook subscription %s not found: %w", delivery.SubscriptionId, err)
+	}
+
+	responseStatus, sendErr := s.sender.Send(ctx, webhook.Request{
+		TargetUrl:  subscription.TargetUrl,
+		Secret:     subscription.Secret,
+		DeliveryId: delivery.ID,
+		EventType:  delivery.EventType,
+		Payload:    []byte(delivery.Payload),
+	})
+
+	delivery.RecordAttempt(responseStatus, sendErr)
+	if _, err := s.webhookDeliveryRepo.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery %s: %w", delivery.ID, err)
+	}
+
+	return sendErr
+}
+
+func (s *webhookService) FindDeliveries(ctx context.Context, subscriptionId string, filterQuery common.FilterQuery) ([]*aggregate.WebhookDelivery, error) {
+	// The subscription must be visible to the caller's tenant
+	if _, err := s.webhookSubscriptionRepo.FindById(ctx, subscriptionId); err != nil {
+		return nil, err
+	}
+
+	filterQuery.Conditions = append(filterQuery.Conditions, common.Condition{
+		Key:      "subscription_id",
+		Value:    subscriptionId,
+		Operator: common.CONDITION_EQ,
+	})
+	filterQuery.Logic = "AND"
+	return s.webhookDeliveryRepo.FindWithFilter(ctx, filterQuery)
+}
+
+// Redeliver sends a logged delivery again, regardless of its previous outcome
+func (s *webhookService) Redeliver(ctx context.Context, deliveryId string) (*aggregate.WebhookDelivery, error) {
+	delivery, err := s.webhookDeliveryRepo.FindById(ctx, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+
+	sendErr := s.Send(ctx, delivery)
+	return delivery, sendErr
+}
+
+// ScheduleRetry records that a failed delivery is attempted again at the given time
+func (s *webhookService) ScheduleRetry(ctx context.Context, delivery *aggregate.WebhookDelivery, at time.Time) error {
+	delivery.ScheduleRetry(at)
+	if _, err := s.webhookDeliveryRepo.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to schedule the retry of webhook delivery %s: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// FindDueDeliveries returns up to limit deliveries whose scheduled retry is due
+func (s *webhookService) FindDueDeliveries(ctx context.Context, limit int) ([]*aggregate.WebhookDelivery, error) {
+	return s.webhookDeliveryRepo.FindDue(ctx, time.Now(), limit)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/webhook"
+)
+
+// fakeSubscriptionRepository keeps subscriptions in memory, the methods the tests do not use panic
+type fakeSubscriptionRepository struct {
+	repository.WebhookSubscriptionRepository
+	subscriptions map[string]*aggregate.WebhookSubscription
+}
+
+func (r *fakeSubscriptionRepository) FindById(ctx context.Context, id string) (*aggregate.WebhookSubscription, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, apperror.NotFound("webhook subscription %s not found", id)
+	}
+	return subscription, nil
+}
+
+// fakeDeliveryRepository keeps copies of the deliveries, as the database would
+type fakeDeliveryRepository struct {
+	repository.WebhookDeliveryRepository
+	deliveries map[string]aggregate.WebhookDelivery
+}
+
+func (r *fakeDeliveryRepository) FindById(ctx context.Context, id string) (*aggregate.WebhookDelivery, error) {
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, apperror.NotFound("webhook delivery %s not found", id)
+	}
+	return &delivery, nil
+}
+
+func (r *fakeDeliveryRepository) Update(ctx context.Context, delivery *aggregate.WebhookDelivery) (*aggregate.WebhookDelivery, error) {
+	r.deliveries[delivery.ID] = *delivery
+	return delivery, nil
+}
+
+// newTestWebhookService returns a service delivering to a receiver answering with the statuses
+// in turn, and the stored delivery "delivery-1" of subscription "subscription-1"
+func newTestWebhookService(t *testing.T, statuses ...int) (WebhookService, *fakeDeliveryRepository) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	subscriptions := &fakeSubscriptionRepository{subscriptions: map[string]*aggregate.WebhookSubscription{
+		"subscription-1": {ID: "subscription-1", TargetUrl: server.URL, Secret: "secret", Active: true},
+	}}
+	deliveries := &fakeDeliveryRepository{deliveries: map[string]aggregate.WebhookDelivery{
+		"delivery-1": {
+			ID:             "delivery-1",
+			SubscriptionId: "subscription-1",
+			EventType:      "CREATED",
+			Payload:        `{"id":"event-1"}`,
+			Status:         aggregate.WEBHOOK_DELIVERY_PENDING,
+		},
+	}}
+	return NewWebhookService(subscriptions, deliveries, webhook.NewSender(server.Client())), deliveries
+}
+
+func TestSendRecordsTheAttempt(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantErr    bool
+		wantStatus aggregate.WebhookDeliveryStatus
+	}{
+		{name: "delivered", status: http.StatusOK, wantStatus: aggregate.WEBHOOK_DELIVERY_SUCCEEDED},
+		{name: "rejected", status: http.StatusServiceUnavailable, wantErr: true, wantStatus: aggregate.WEBHOOK_DELIVERY_FAILED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, deliveries := newTestWebhookService(t, tt.status)
+			delivery, _ := deliveries.FindById(ctx, "delivery-1")
+
+			err := service.Send(ctx, delivery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send returned %v, want an error: %t", err, tt.wantErr)
+			}
+
+			stored := deliveries.deliveries["delivery-1"]
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if stored.Attempts != 1 {
+				t.Errorf("attempts = %d, want 1", stored.Attempts)
+			}
+			if stored.ResponseStatus != tt.status {
+				t.Errorf("response status = %d, want %d", stored.ResponseStatus, tt.status)
+			}
+			if tt.wantErr && stored.LastError == "" {
+				t.Error("the error of the failed attempt is not recorded")
+			}
+			if !tt.wantErr && stored.DeliveredAt == nil {
+				t.Error("the time of the delivery is not recorded")
+			}
+		})
+	}
+}
+
+func TestScheduleRetry(t *testing.T) {
+	ctx := context.Background()
+	service, deliveries := newTestWebhookService(t, http.StatusInternalServerError)
+	delivery, _ := deliveries.FindById(ctx, "delivery-1")
+
+	if err := service.Send(ctx, delivery); err == nil {
+		t.Fatal("Send returned no error for a 500")
+	}
+	at := time.Now().Add(time.Minute)
+	if err := service.ScheduleRetry(ctx, delivery, at); err != nil {
+		t.Fatalf("ScheduleRetry returned %v", err)
+	}
+
+	stored := deliveries.deliveries["delivery-1"]
+	if stored.Status != aggregate.WEBHOOK_DELIVERY_RETRYING {
+		t.Errorf("status = %s, want %s", stored.Status, aggregate.WEBHOOK_DELIVERY_RETRYING)
+	}
+	if stored.NextAttemptAt == nil || !stored.NextAttemptAt.Equal(at) {
+		t.Errorf("next attempt at = %v, want %v", stored.NextAttemptAt, at)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	ctx := context.Background()
+	service, deliveries := newTestWebhookService(t, http.StatusBadGateway, http.StatusAccepted)
+	delivery, _ := deliveries.FindById(ctx, "delivery-1")
+	if err := service.Send(ctx, delivery); err == nil {
+		t.Fatal("Send returned no error for a 502")
+	}
+	if err := service.ScheduleRetry(ctx, delivery, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("ScheduleRetry returned %v", err)
+	}
+
+	redelivered, err := service.Redeliver(ctx, "delivery-1")
+	if err != nil {
+		t.Fatalf("Redeliver returned %v", err)
+	}
+
+	stored := deliveries.deliveries["delivery-1"]
+	if stored.Status != aggregate.WEBHOOK_DELIVERY_SUCCEEDED || redelivered.Status != aggregate.WEBHOOK_DELIVERY_SUCCEEDED {
+		t.Errorf("status = %s, want %s", stored.Status, aggregate.WEBHOOK_DELIVERY_SUCCEEDED)
+	}
+	if stored.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", stored.Attempts)
+	}
+	if stored.ResponseStatus != http.StatusAccepted {
+		t.Errorf("response status = %d, want %d", stored.ResponseStatus, http.StatusAccepted)
+	}
+	if stored.LastError != "" {
+		t.Errorf("last error = %q, want it cleared", stored.LastError)
+	}
+	if stored.NextAttemptAt != nil {
+		t.Errorf("next attempt at = %v, want the scheduled retry cancelled", stored.NextAttemptAt)
+	}
+}
+
+func TestRedeliverUnknownDelivery(t *testing.T) {
+	service, _ := newTestWebhookService(t, http.StatusOK)
+
+	_, err := service.Redeliver(context.Background(), "missing")
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("Redeliver returned %v, want a not found error", err)
+	}
+}
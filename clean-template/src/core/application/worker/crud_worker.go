@@ -2,29 +2,136 @@ package worker
 
 import (
 	"context"
-	"log"
 	"time"
 
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/event_bus"
 	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// StartCRUDWorker listens to the CRUD channel and processes data
-func StartCRUDEventWorker(ctx context.Context) {
+// StartCRUDWorker listens to the CRUD channel and stores events in batches
+func StartCRUDEventWorker(ctx context.Context, worker Worker) {
 	crudEventChannel := worker_channel.GetCRUDEventChannel()
+
+	ticker := time.NewTicker(worker.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]common.Event, 0, worker.BatchSize)
+	add := func(event common.Event) {
+		if !event.Config.EventStore {
+			dispatchEvents(event)
+			return
+		}
+		batch = append(batch, event)
+		if len(batch) >= worker.BatchSize {
+			storeEvents(ctx, worker, batch)
+			batch = batch[:0]
+		}
+	}
+	flush := func() {
+		if len(batch) > 0 {
+			// The worker's logger is kept, only the cancellation is dropped, the batch is off the channel
+			storeEvents(context.WithoutCancel(ctx), worker, batch)
+			batch = batch[:0]
+		}
+	}
+
 	for {
+		health.Idle(ctx)
 		select {
 		case event := <-crudEventChannel: // Listen to the channel
-			// Simulate processing, e.g., saving to database
-			if event.Config.EventStore {
-				AllServices := service.GetServices()
-				AllServices.EventService.Create(event)
+			health.Beat(ctx)
+			add(event)
+		case <-ticker.C:
+			health.Beat(ctx)
+			if len(batch) > 0 {
+				storeEvents(ctx, worker, batch)
+				batch = batch[:0]
 			}
-			// Optional: Add any delay or logic as required
-			time.Sleep(1 * time.Second)
+		case <-worker.Stopping():
+			// Events already buffered on the channel are stored before the worker returns
+			drain(ctx, crudEventChannel, add)
+			flush()
+			logger.FromContext(ctx).Info("shutting down CRUD event worker")
+			return
 		case <-ctx.Done():
-			log.Println("Shutting down CRUD Event worker...")
+			flush()
+			logger.FromContext(ctx).Info("shutting down CRUD event worker")
 			return
 		}
 	}
 }
+
+// storeEvents inserts the batch with retries. When the batch keeps failing the events are
+// stored one by one so a single poisoned event only sends itself to the dead-letter table.
+func storeEvents(ctx context.Context, worker Worker, batch []common.Event) {
+	eventService := service.GetServices().EventService
+
+	// Every event continues the trace of the request that produced it. The batch insert is done
+	// for many traces at once, so its span links to them instead.
+	eventContexts := make([]context.Context, len(batch))
+	eventSpans := make([]trace.Span, len(batch))
+	links := make([]trace.Link, len(batch))
+	for index, event := range batch {
+		eventContexts[index], eventSpans[index] = tracing.Start(withEvent(ctx, event), "CRUDWorker.storeEvent", trace.WithSpanKind(trace.SpanKindConsumer))
+		links[index] = trace.LinkFromContext(eventContexts[index])
+	}
+
+	batchCtx, batchSpan := tracing.Start(ctx, "CRUDWorker.storeBatch", trace.WithLinks(links...), trace.WithAttributes(attribute.Int("events.count", len(batch))))
+	_, err := retryWithBackoff(batchCtx, worker.Retry, func() error {
+		_, err := eventService.CreateBatch(batchCtx, batch)
+		return err
+	})
+	tracing.End(batchSpan, err)
+	if err == nil {
+		for _, eventSpan := range eventSpans {
+			eventSpan.End()
+		}
+		metrics.WorkerEventsProcessed.WithLabelValues(worker.Name).Add(float64(len(batch)))
+		dispatchEvents(batch...)
+		return
+	}
+
+	logger.FromContext(ctx).Warn("failed to store batch of events, storing individually", "count", len(batch), "error", err)
+
+	for index, event := range batch {
+		eventCtx := eventContexts[index]
+		attempts, err := retryWithBackoff(eventCtx, worker.Retry, func() error {
+			_, err := eventService.Create(eventCtx, event)
+			return err
+		})
+		if err == nil {
+			eventSpans[index].End()
+			metrics.WorkerEventsProcessed.WithLabelValues(worker.Name).Inc()
+			dispatchEvents(event)
+			continue
+		}
+
+		metrics.WorkerEventsFailed.WithLabelValues(worker.Name).Inc()
+
+		log := logger.FromContext(eventCtx)
+		log.Error("moving event to dead letter", "attempts", attempts, "error", err)
+		// Parking the event must not be cut short by a shutdown
+		if deadLetterErr := eventService.DeadLetter(context.WithoutCancel(eventCtx), event, worker_channel.CHANNEL_CRUD, err, attempts); deadLetterErr != nil {
+			log.Error("failed to dead letter event", "error", deadLetterErr)
+		}
+		tracing.End(eventSpans[index], err)
+	}
+}
+
+// dispatchEvents hands events over to the consumers that act on stored events
+func dispatchEvents(events ...common.Event) {
+	for _, event := range events {
+		worker_channel.PushToWebhookChannel(event)
+		if len(event_bus.SubscriptionsFor(event)) > 0 {
+			worker_channel.PushToDomainEventChannel(event)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/event_bus"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartDomainEventWorker runs the in-process event bus subscribers of stored CRUD events
+func StartDomainEventWorker(ctx context.Context, worker Worker) {
+	domainEventChannel := worker_channel.GetDomainEventChannel()
+	for {
+		health.Idle(ctx)
+		select {
+		case event := <-domainEventChannel:
+			health.Beat(ctx)
+			handleDomainEvents(ctx, worker, event)
+		case <-worker.Stopping():
+			drain(ctx, domainEventChannel, func(event common.Event) {
+				handleDomainEvents(ctx, worker, event)
+			})
+			logger.FromContext(ctx).Info("shutting down domain event worker")
+			return
+		case <-ctx.Done():
+			logger.FromContext(ctx).Info("shutting down domain event worker")
+			return
+		}
+	}
+}
+
+func handleDomainEvents(ctx context.Context, worker Worker, event common.Event) {
+	for _, subscription := range event_bus.SubscriptionsFor(event) {
+		handleDomainEvent(ctx, worker, subscription, event)
+	}
+}
+
+// handleDomainEvent runs one subscription with retries, isolated from the other subscriptions
+func handleDomainEvent(ctx context.Context, worker Worker, subscription event_bus.Subscription, event common.Event) {
+	// Handlers act for the tenant of the record, their repository calls are scoped to it
+	if event.TenantID != "" {
+		ctx = common.WithTenant(ctx, event.TenantID)
+	}
+	ctx = logger.With(withEvent(ctx, event), "subscription", subscription.Name)
+	ctx, span := tracing.Start(ctx, "DomainEventWorker."+subscription.Name, trace.WithSpanKind(trace.SpanKindConsumer))
+
+	attempts, err := retryWithBackoff(ctx, worker.Retry, func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return subscription.Handle(ctx, event)
+	})
+	if err == nil {
+		span.End()
+		metrics.WorkerEventsProcessed.WithLabelValues(worker.Name).Inc()
+		return
+	}
+
+	metrics.WorkerEventsFailed.WithLabelValues(worker.Name).Inc()
+
+	log := logger.FromContext(ctx)
+	log.Error("domain event handler failed, moving event to dead letter", "attempts", attempts, "error", err)
+
+	reason := fmt.Errorf("handler %s: %w", subscription.Name, err)
+	if deadLetterErr := service.GetServices().EventService.DeadLetter(context.WithoutCancel(ctx), event, worker_channel.CHANNEL_DOMAIN_EVENT, reason, attempts); deadLetterErr != nil {
+		log.Error("failed to dead letter event", "error", deadLetterErr)
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+)
+
+// RetryPolicy controls the exponential backoff applied to failing worker operations
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// retryWithBackoff calls fn until it succeeds, the attempts are exhausted or ctx is done.
+// It returns the number of attempts made together with the last error.
+func retryWithBackoff(ctx context.Context, policy RetryPolicy, fn func() error) (int, error) {
+	attempts := 0
+
+	for {
+		attempts++
+		// Every attempt is progress, a worker waiting out a long backoff is not stuck
+		health.Beat(ctx)
+		err := fn()
+		if err == nil {
+			return attempts, nil
+		}
+		backoff, retry := policy.backoff(attempts)
+		if !retry {
+			return attempts, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// backoff returns the wait before the attempt following the given number of failed attempts,
+// false once the attempts are exhausted
+func (p RetryPolicy) backoff(attempts int) (time.Duration, bool) {
+	if attempts >= p.MaxAttempts {
+		return 0, false
+	}
+	backoff := p.InitialBackoff
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff), true
+}
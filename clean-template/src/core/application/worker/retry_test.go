@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	tests := []struct {
+		attempts  int
+		wantWait  time.Duration
+		wantRetry bool
+	}{
+		{attempts: 1, wantWait: time.Second, wantRetry: true},
+		{attempts: 2, wantWait: 2 * time.Second, wantRetry: true},
+		{attempts: 3, wantWait: 4 * time.Second, wantRetry: true},
+		{attempts: 4, wantWait: 5 * time.Second, wantRetry: true},
+		{attempts: 5, wantRetry: false},
+	}
+	for _, tt := range tests {
+		wait, retry := policy.backoff(tt.attempts)
+		if wait != tt.wantWait || retry != tt.wantRetry {
+			t.Errorf("backoff(%d) = %s, %t, want %s, %t", tt.attempts, wait, retry, tt.wantWait, tt.wantRetry)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduledJob is a job run on a schedule instead of a long-running loop.
+// Schedule accepts a cron expression ("0 3 * * *"), a descriptor ("@daily", "@every 10m")
+// or a plain interval ("10m"). A run is skipped while the previous run is still in progress.
+// With LeaderElection only the replica holding the job's Postgres advisory lock runs it.
+type ScheduledJob struct {
+	Name           string
+	Schedule       string
+	LeaderElection bool
+	Handler        func(ctx context.Context) error
+}
+
+var ScheduledJobs = []ScheduledJob{
+	{Name: "webhook_retry", Schedule: "@every 5s", LeaderElection: true, Handler: RetryWebhookDeliveries},
+	// Jobs added by `gStructify add worker -name=<name> -schedule=<schedule>` are listed here
+}
+
+// StartScheduler runs the scheduled jobs until stopping is closed, then waits for the running jobs.
+// Jobs run with ctx, which is only cancelled when the shutdown deadline passes.
+func StartScheduler(ctx context.Context, stopping <-chan struct{}) {
+	if len(ScheduledJobs) == 0 {
+		return
+	}
+
+	// The standard logger is routed through slog
+	cronLogger := cron.PrintfLogger(log.Default())
+	scheduler := cron.New(cron.WithChain(cron.Recover(cronLogger), cron.SkipIfStillRunning(cronLogger)))
+
+	for _, job := range ScheduledJobs {
+		job := job
+		schedule := toCronSchedule(job.Schedule)
+		if _, err := scheduler.AddFunc(schedule, func() { runScheduledJob(ctx, job) }); err != nil {
+			slog.Error("invalid job schedule", "job", job.Name, "schedule", job.Schedule, "error", err)
+			continue
+		}
+		slog.Info("scheduled job", "job", job.Name, "schedule", job.Schedule)
+	}
+
+	scheduler.Start()
+
+	select {
+	case <-stopping:
+	case <-ctx.Done():
+	}
+	slog.Info("shutting down scheduler")
+	// Wait for running jobs to finish
+	<-scheduler.Stop().Done()
+}
+
+func runScheduledJob(ctx context.Context, job ScheduledJob) {
+	ctx = logger.With(ctx, "job", job.Name)
+	log := logger.FromContext(ctx)
+
+	if job.LeaderElection {
+		unlock, acquired, err := db.ConnectAll().SqlDB.TryAdvisoryLock(ctx, "job:"+job.Name)
+		if err != nil {
+			log.Error("job skipped, leader election failed", "error", err)
+			return
+		}
+		if !acquired {
+			// Another replica is running this job
+			return
+		}
+		defer unlock()
+	}
+
+	start := time.Now()
+	if err := job.Handler(ctx); err != nil {
+		log.Error("job failed", "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		return
+	}
+	log.Info("job finished", "duration_ms", time.Since(start).Milliseconds())
+}
+
+// toCronSchedule turns a plain interval like "10m" into "@every 10m"
+func toCronSchedule(schedule string) string {
+	schedule = strings.TrimSpace(schedule)
+	if _, err := time.ParseDuration(schedule); err == nil {
+		return "@every " + schedule
+	}
+	return schedule
+}
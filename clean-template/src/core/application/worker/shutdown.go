@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+)
+
+// parkTimeout bounds moving the events left on the channels to the dead letter table, and moving
+// them back on boot
+const parkTimeout = 10 * time.Second
+
+// Workers are the running scheduler and workers, started by InitializeWorkers
+type Workers struct {
+	// cancel cancels the context of every worker, once the shutdown deadline passes
+	cancel context.CancelFunc
+	stages []*workerStage
+}
+
+// workerStage is one worker with all of its copies, or the scheduler
+type workerStage struct {
+	name     string
+	stopping chan struct{}
+	wait     sync.WaitGroup
+}
+
+func (w *Workers) addStage(name string) *workerStage {
+	stage := &workerStage{name: name, stopping: make(chan struct{})}
+	w.stages = append(w.stages, stage)
+	return stage
+}
+
+// Stop stops the scheduler and the workers in the order they were started, each after the previous
+// one returned, so events the CRUD worker forwards are still delivered to webhooks and subscribers.
+// Each worker handles the events it has buffered first. When ctx is done before, the work still running
+// is cancelled. Events left on the channels are moved to the dead letter table, a deploy does not lose them.
+func (w *Workers) Stop(ctx context.Context) error {
+	var err error
+	for index, stage := range w.stages {
+		close(stage.stopping)
+		if waitErr := waitFor(ctx, &stage.wait); waitErr != nil {
+			err = fmt.Errorf("%s did not stop in time: %w", stage.name, waitErr)
+			slog.Warn("shutdown deadline passed, cancelling workers", "worker", stage.name)
+
+			w.cancel()
+			for _, remaining := range w.stages[index+1:] {
+				close(remaining.stopping)
+			}
+			for _, remaining := range w.stages[index:] {
+				remaining.wait.Wait()
+			}
+			break
+		}
+		slog.Info("workers stopped", "worker", stage.name)
+	}
+	w.cancel()
+
+	parkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), parkTimeout)
+	defer cancel()
+	parkBufferedEvents(parkCtx, worker_channel.CHANNEL_CRUD, worker_channel.GetCRUDEventChannel())
+	parkBufferedEvents(parkCtx, worker_channel.CHANNEL_WEBHOOK, worker_channel.GetWebhookEventChannel())
+	parkBufferedEvents(parkCtx, worker_channel.CHANNEL_DOMAIN_EVENT, worker_channel.GetDomainEventChannel())
+
+	return err
+}
+
+// waitFor waits for wait, or returns the error of ctx when it is done first
+func waitFor(ctx context.Context, wait *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wait.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parkBufferedEvents moves the events left on channel to the dead letter table
+func parkBufferedEvents(ctx context.Context, name string, channel chan common.Event) {
+	reason := fmt.Errorf("shutdown before the %s channel was drained", name)
+
+	parked := 0
+	for {
+		select {
+		case event := <-channel:
+			if err := service.GetServices().EventService.DeadLetter(ctx, event, name, reason, 0); err != nil {
+				slog.Error("failed to dead letter event on shutdown", "channel", name, "event_id", event.ID, "error", err)
+				continue
+			}
+			parked++
+		default:
+			if parked > 0 {
+				slog.Warn("moved buffered events to dead letter", "channel", name, "count", parked)
+			}
+			return
+		}
+	}
+}
+
+// requeueParkedEvents puts the events parked by the last shutdown back on their channels, once the
+// workers run. Only the replica holding the lock requeues them, so an event is not handled twice.
+func requeueParkedEvents(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, parkTimeout)
+	defer cancel()
+
+	unlock, acquired, err := db.ConnectAll().SqlDB.TryAdvisoryLock(ctx, "requeue-parked-events")
+	if err != nil {
+		slog.Error("failed to requeue parked events, leader election failed", "error", err)
+		return
+	}
+	if !acquired {
+		// Another replica is requeuing them
+		return
+	}
+	defer unlock()
+
+	requeued, err := service.GetServices().EventService.RequeueParkedEvents(ctx)
+	if err != nil {
+		slog.Error("failed to requeue parked events, the rest stay in the dead letter table", "requeued", requeued, "error", err)
+		return
+	}
+	if requeued > 0 {
+		slog.Info("requeued events parked at shutdown", "count", requeued)
+	}
+}
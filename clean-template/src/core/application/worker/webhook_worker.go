@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	WEBHOOK_WORKER_NAME = "Webhook Worker"
+	// WEBHOOK_RETRY_BATCH_SIZE caps the deliveries one run of the retry job attempts
+	WEBHOOK_RETRY_BATCH_SIZE = 100
+)
+
+// WebhookRetryPolicy spaces the attempts of a webhook delivery, see RetryWebhookDeliveries
+var WebhookRetryPolicy = RetryPolicy{
+	MaxAttempts:    8,
+	InitialBackoff: 1 * time.Second,
+	MaxBackoff:     5 * time.Minute,
+}
+
+// StartWebhookWorker delivers stored CRUD events to the matching webhook subscriptions
+func StartWebhookWorker(ctx context.Context, worker Worker) {
+	webhookEventChannel := worker_channel.GetWebhookEventChannel()
+	for {
+		health.Idle(ctx)
+		select {
+		case event := <-webhookEventChannel:
+			health.Beat(ctx)
+			deliverEvent(ctx, worker, event)
+		case <-worker.Stopping():
+			drain(ctx, webhookEventChannel, func(event common.Event) {
+				deliverEvent(ctx, worker, event)
+			})
+			logger.FromContext(ctx).Info("shutting down webhook worker")
+			return
+		case <-ctx.Done():
+			logger.FromContext(ctx).Info("shutting down webhook worker")
+			return
+		}
+	}
+}
+
+func deliverEvent(ctx context.Context, worker Worker, event common.Event) {
+	webhookService := service.GetServices().WebhookService
+	ctx, span := tracing.Start(withEvent(ctx, event), "WebhookWorker.deliverEvent", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+	log := logger.FromContext(ctx)
+
+	subscriptions, err := webhookService.FindSubscriptionsForEvent(ctx, event)
+	if err != nil {
+		metrics.WorkerEventsFailed.WithLabelValues(worker.Name).Inc()
+		span.RecordError(err)
+		log.Error("failed to load webhook subscriptions", "error", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		delivery, err := webhookService.CreateDelivery(ctx, subscription, event)
+		if err != nil {
+			metrics.WorkerEventsFailed.WithLabelValues(worker.Name).Inc()
+			span.RecordError(err)
+			log.Error("failed to log webhook delivery", "target_url", subscription.TargetUrl, "error", err)
+			continue
+		}
+
+		attemptDelivery(ctx, worker.Name, delivery)
+	}
+}
+
+// RetryWebhookDeliveries attempts the failed deliveries whose retry is due. Failed deliveries are
+// retried by this job rather than by the worker, so a slow receiver never blocks the worker.
+func RetryWebhookDeliveries(ctx context.Context) error {
+	// Deliveries of every tenant are retried
+	ctx = common.WithAllTenants(ctx)
+	deliveries, err := service.GetServices().WebhookService.FindDueDeliveries(ctx, WEBHOOK_RETRY_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		attemptDelivery(logger.With(ctx, "event_id", delivery.EventId), WEBHOOK_WORKER_NAME, delivery)
+	}
+	return nil
+}
+
+// attemptDelivery makes one delivery attempt and schedules the next one when it fails, until the
+// attempts of WebhookRetryPolicy are exhausted
+func attemptDelivery(ctx context.Context, name string, delivery *aggregate.WebhookDelivery) {
+	webhookService := service.GetServices().WebhookService
+	log := logger.FromContext(ctx).With("delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionId, "attempts", delivery.Attempts+1)
+
+	err := webhookService.Send(ctx, delivery)
+	if err == nil {
+		metrics.WorkerEventsProcessed.WithLabelValues(name).Inc()
+		return
+	}
+
+	backoff, retry := WebhookRetryPolicy.backoff(delivery.Attempts)
+	if !retry {
+		// The delivery log keeps the failure; it can be redelivered through the API
+		metrics.WorkerEventsFailed.WithLabelValues(name).Inc()
+		log.Error("gave up delivering webhook", "error", err)
+		return
+	}
+	if scheduleErr := webhookService.ScheduleRetry(ctx, delivery, time.Now().Add(backoff)); scheduleErr != nil {
+		metrics.WorkerEventsFailed.WithLabelValues(name).Inc()
+		log.Error("failed to schedule the webhook retry", "error", scheduleErr)
+		return
+	}
+	log.Warn("webhook delivery failed, retry scheduled", "retry_in", backoff.String(), "error", err)
+}
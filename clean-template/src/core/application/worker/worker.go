@@ -2,44 +2,153 @@ package worker
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
 )
 
+// Worker describes a background worker. Concurrency copies of Handler run in parallel,
+// batch workers collect up to BatchSize items and flush them at least every FlushInterval.
+// Handler returns once Stopping is closed, after handling the items it has buffered.
 type Worker struct {
-	Name    string
-	Handler func(ctx context.Context)
+	Name          string
+	Concurrency   int
+	BatchSize     int
+	FlushInterval time.Duration
+	Retry         RetryPolicy
+	Handler       func(ctx context.Context, worker Worker)
+
+	stopping <-chan struct{}
+}
+
+// Stopping is closed when the worker must handle the items it has buffered and return. The
+// context of the handler is only cancelled when the shutdown deadline passes.
+func (w Worker) Stopping() <-chan struct{} {
+	return w.stopping
 }
 
-func InitializeWorkers(ctx context.Context) {
+// InitializeWorkers starts the scheduler and the workers, Workers.Stop stops them
+func InitializeWorkers(ctx context.Context, settings config.WorkerConfig) *Workers {
 	workers := []Worker{
-		{Name: "CRUD Worker", Handler: StartCRUDEventWorker},
+		{
+			Name:          "CRUD Worker",
+			Concurrency:   settings.Concurrency,
+			BatchSize:     500,
+			FlushInterval: 1 * time.Second,
+			Retry:         DefaultRetryPolicy,
+			Handler:       StartCRUDEventWorker,
+		},
+		{
+			Name:        WEBHOOK_WORKER_NAME,
+			Concurrency: settings.Concurrency,
+			Retry:       WebhookRetryPolicy,
+			Handler:     StartWebhookWorker,
+		},
+		{
+			Name:        "Domain Event Worker",
+			Concurrency: settings.Concurrency,
+			Retry:       DefaultRetryPolicy,
+			Handler:     StartDomainEventWorker,
+		},
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	running := &Workers{cancel: cancel}
+
+	// Start scheduled jobs, they are stopped first as they may produce events
+	scheduler := running.addStage("Scheduler")
+	scheduler.wait.Add(1)
+	go func() {
+		defer scheduler.wait.Done()
+		StartScheduler(ctx, scheduler.stopping)
+	}()
+
 	for _, worker := range workers {
-		go startWorkerWithRecovery(ctx, worker)
+		stage := running.addStage(worker.Name)
+		worker.stopping = stage.stopping
+
+		concurrency := worker.Concurrency
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		for i := 0; i < concurrency; i++ {
+			// A copy that stops making progress on its work fails /livez
+			heartbeat := health.NewHeartbeat(fmt.Sprintf("worker %s #%d", worker.Name, i+1), heartbeatMaxAge(worker))
+			health.RegisterLiveness(heartbeat)
+
+			stage.wait.Add(1)
+			go func(worker Worker) {
+				defer stage.wait.Done()
+				startWorkerWithRecovery(health.WithHeartbeat(ctx, heartbeat), worker)
+			}(worker)
+		}
 	}
+
+	// Events parked by the last shutdown are handled now the workers run
+	go requeueParkedEvents(ctx)
+
+	return running
 }
 
 func startWorkerWithRecovery(ctx context.Context, worker Worker) {
+	ctx = logger.With(ctx, "worker", worker.Name)
+	log := logger.FromContext(ctx)
+
 	for {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error("worker panic recovered, restarting", "panic", r)
+				}
+			}()
+			log.Info("worker started")
+			worker.Handler(ctx, worker)
+		}()
+
+		// Restart after a small delay, unless the worker was stopped
 		select {
 		case <-ctx.Done():
-			log.Printf("%s stopped.\n", worker.Name)
+			log.Info("worker stopped")
+			return
+		case <-worker.stopping:
+			log.Info("worker stopped")
 			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// drain hands the items buffered on channel to handle until it is empty, or until ctx is cancelled
+// and the remaining items are left for Workers.Stop to dead letter
+func drain[T any](ctx context.Context, channel chan T, handle func(item T)) {
+	for ctx.Err() == nil {
+		select {
+		case item := <-channel:
+			health.Beat(ctx)
+			handle(item)
 		default:
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("%s panic recovered: %v. Restarting...\n", worker.Name, r)
-					}
-				}()
-				log.Printf("%s started.\n", worker.Name)
-				worker.Handler(ctx)
-			}()
+			return
 		}
+	}
+}
+
+// heartbeatMaxAge is how long a busy worker may go without progress. Retries beat on every
+// attempt, so the longest gap is one backoff plus one attempt.
+func heartbeatMaxAge(worker Worker) time.Duration {
+	return worker.Retry.MaxBackoff + 2*time.Minute
+}
 
-		// Optional: Add a small delay before restarting
-		time.Sleep(2 * time.Second)
+// withEvent adds the event and the request that produced it to the context and its logger,
+// so the logs and spans of a worker correlate with that request.
+func withEvent(ctx context.Context, event common.Event) context.Context {
+	if event.RequestID != "" {
+		ctx = common.WithRequestID(ctx, event.RequestID)
 	}
+	ctx = tracing.Extract(ctx, event.TraceContext)
+	return logger.With(ctx, "event_id", event.ID, "request_id", event.RequestID, "trace_id", tracing.TraceID(ctx))
 }
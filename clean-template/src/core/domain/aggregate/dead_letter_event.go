@@ -0,0 +1,51 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/helper"
+)
+
+// DeadLetterEvent is an event the worker gave up on after exhausting its retries. Channel is the
+// worker channel it was taken from and Payload the whole event, so it can be replayed there.
+// Events parked at shutdown before a worker took them have no Attempts and are requeued on boot.
+type DeadLetterEvent struct {
+	ID         string
+	EventId    string
+	EntityId   string
+	EntityName string
+	Type       string
+	Channel    string
+	Payload    string
+	Error      string
+	Attempts   int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewDeadLetterEvent(event common.Event, channel string, reason error, attempts int) *DeadLetterEvent {
+	payload, _ := json.Marshal(event)
+
+	return &DeadLetterEvent{
+		ID:         helper.Generate16DigitUUID(),
+		EventId:    event.ID,
+		EntityId:   event.EntityId,
+		EntityName: string(event.EntityName),
+		Type:       string(event.Type),
+		Channel:    channel,
+		Payload:    string(payload),
+		Error:      reason.Error(),
+		Attempts:   attempts,
+	}
+}
+
+// Event decodes the parked event, dead letters recorded before the payload was kept have none
+func (d *DeadLetterEvent) Event() (common.Event, bool) {
+	var event common.Event
+	if d.Payload == "" || json.Unmarshal([]byte(d.Payload), &event) != nil {
+		return event, false
+	}
+	return event, true
+}
@@ -1,6 +1,7 @@
 package aggregate
 
 import (
+	"context"
 	"time"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
@@ -11,17 +12,28 @@ type Event struct {
 	ID         string
 	EntityId   string
 	EntityName string
+	TenantID   string
+	Actor      string
 	Type       string
+	Data       string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
 
 func NewEvent(createDTO common.Event) *Event {
+	id := createDTO.ID
+	if id == "" {
+		id = helper.Generate16DigitUUID() // Generate unique ID (UUID or similar)
+	}
 	return &Event{
-		ID:         helper.Generate16DigitUUID(), // Generate unique ID (UUID or similar)
+		ID:         id, // Reusing the published ID keeps retried inserts idempotent
 		EntityId:   createDTO.EntityId,
 		EntityName: string(createDTO.EntityName),
+		TenantID:   createDTO.TenantID,
+		Actor:      createDTO.Actor,
 		Type:       string(createDTO.Type),
+		Data:       createDTO.Data,
+		CreatedAt:  createDTO.OccurredAt,
 	}
 }
 
@@ -30,14 +42,20 @@ func UpdateEvent(id string, updateDTO common.Event) *Event {
 		ID:         id,
 		EntityId:   updateDTO.EntityId,
 		EntityName: string(updateDTO.EntityName),
+		TenantID:   updateDTO.TenantID,
+		Actor:      updateDTO.Actor,
 		Type:       string(updateDTO.Type),
+		Data:       updateDTO.Data,
 	}
 }
 
 type EventRepository interface {
-	Create(event *Event) (*Event, error)
-	FindById(id string) (*Event, error)
-	FindWithFilter(filterQuery common.FilterQuery) ([]*Event, error)
-	Update(event *Event) (*Event, error)
-	Delete(id string) error
+	Create(ctx context.Context, event *Event) (*Event, error)
+	BulkCreate(ctx context.Context, events []*Event) ([]*Event, error)
+	FindById(ctx context.Context, id string) (*Event, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*Event, error)
+	FindByEntity(ctx context.Context, entityName common.EntityName, entityId string) ([]*Event, error)
+	FindLastByEntity(ctx context.Context, entityName common.EntityName, entityId string, at time.Time) (*Event, error)
+	Update(ctx context.Context, event *Event) (*Event, error)
+	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,99 @@
+package aggregate
+
+// Lifecycle hooks an aggregate can implement to take part in its own persistence.
+// Before hooks run inside the database transaction ahead of the write; returning an
+// error vetoes the operation and rolls the transaction back. After hooks run inside the
+// same transaction once the write succeeded; returning an error also rolls it back.
+// Events are only emitted after the transaction commits.
+// Veto with an apperror, e.g. apperror.Validation("..."), to answer the request with its
+// status; any other error is answered as an internal error.
+
+type BeforeCreateHook interface {
+	BeforeCreate() error
+}
+
+type AfterCreateHook interface {
+	AfterCreate() error
+}
+
+// BeforeUpdateHook receives the currently stored state of the aggregate
+type BeforeUpdateHook[T any] interface {
+	BeforeUpdate(previous *T) error
+}
+
+// AfterUpdateHook receives the state of the aggregate before the update
+type AfterUpdateHook[T any] interface {
+	AfterUpdate(previous *T) error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete() error
+}
+
+type AfterDeleteHook interface {
+	AfterDelete() error
+}
+
+type BeforeRestoreHook interface {
+	BeforeRestore() error
+}
+
+type AfterRestoreHook interface {
+	AfterRestore() error
+}
+
+func RunBeforeCreate(aggregate any) error {
+	if hook, ok := aggregate.(BeforeCreateHook); ok {
+		return hook.BeforeCreate()
+	}
+	return nil
+}
+
+func RunAfterCreate(aggregate any) error {
+	if hook, ok := aggregate.(AfterCreateHook); ok {
+		return hook.AfterCreate()
+	}
+	return nil
+}
+
+func RunBeforeUpdate[T any](aggregate *T, previous *T) error {
+	if hook, ok := any(aggregate).(BeforeUpdateHook[T]); ok {
+		return hook.BeforeUpdate(previous)
+	}
+	return nil
+}
+
+func RunAfterUpdate[T any](aggregate *T, previous *T) error {
+	if hook, ok := any(aggregate).(AfterUpdateHook[T]); ok {
+		return hook.AfterUpdate(previous)
+	}
+	return nil
+}
+
+func RunBeforeDelete(aggregate any) error {
+	if hook, ok := aggregate.(BeforeDeleteHook); ok {
+		return hook.BeforeDelete()
+	}
+	return nil
+}
+
+func RunAfterDelete(aggregate any) error {
+	if hook, ok := aggregate.(AfterDeleteHook); ok {
+		return hook.AfterDelete()
+	}
+	return nil
+}
+
+func RunBeforeRestore(aggregate any) error {
+	if hook, ok := aggregate.(BeforeRestoreHook); ok {
+		return hook.BeforeRestore()
+	}
+	return nil
+}
+
+func RunAfterRestore(aggregate any) error {
+	if hook, ok := aggregate.(AfterRestoreHook); ok {
+		return hook.AfterRestore()
+	}
+	return nil
+}
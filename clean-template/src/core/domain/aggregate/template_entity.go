@@ -7,6 +7,19 @@ import (
 	"github.com/nanda03dev/go-ms-template/src/helper"
 )
 
+// TemplateEntity lifecycle hooks, see hooks.go. Implement any of them on *TemplateEntity
+// in a separate file of this package to veto or enrich a templateEntity operation.
+type (
+	TemplateEntityBeforeCreateHook  = BeforeCreateHook
+	TemplateEntityAfterCreateHook   = AfterCreateHook
+	TemplateEntityBeforeUpdateHook  = BeforeUpdateHook[TemplateEntity]
+	TemplateEntityAfterUpdateHook   = AfterUpdateHook[TemplateEntity]
+	TemplateEntityBeforeDeleteHook  = BeforeDeleteHook
+	TemplateEntityAfterDeleteHook   = AfterDeleteHook
+	TemplateEntityBeforeRestoreHook = BeforeRestoreHook
+	TemplateEntityAfterRestoreHook  = AfterRestoreHook
+)
+
 type TemplateEntity struct {
 	ID        string
 	#@$Field$ $FieldType$#@
@@ -0,0 +1,71 @@
+package aggregate
+
+import (
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/helper"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WEBHOOK_DELIVERY_PENDING   WebhookDeliveryStatus = "PENDING"
+	WEBHOOK_DELIVERY_SUCCEEDED WebhookDeliveryStatus = "SUCCEEDED"
+	WEBHOOK_DELIVERY_RETRYING  WebhookDeliveryStatus = "RETRYING"
+	WEBHOOK_DELIVERY_FAILED    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is the delivery log of one event to one subscription
+type WebhookDelivery struct {
+	ID             string
+	TenantID       string
+	SubscriptionId string
+	EventId        string
+	EventType      string
+	Payload        string
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  *time.Time // Set while a retry is scheduled
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewWebhookDelivery(subscriptionId string, event common.Event, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:             helper.Generate16DigitUUID(),
+		TenantID:       event.TenantID,
+		SubscriptionId: subscriptionId,
+		EventId:        event.ID,
+		EventType:      string(event.Type),
+		Payload:        payload,
+		Status:         WEBHOOK_DELIVERY_PENDING,
+	}
+}
+
+// RecordAttempt updates the delivery log with the outcome of one attempt
+func (d *WebhookDelivery) RecordAttempt(responseStatus int, err error) {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.NextAttemptAt = nil
+
+	if err != nil {
+		d.Status = WEBHOOK_DELIVERY_FAILED
+		d.LastError = err.Error()
+		return
+	}
+
+	now := time.Now()
+	d.Status = WEBHOOK_DELIVERY_SUCCEEDED
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// ScheduleRetry marks a failed delivery to be attempted again at the given time
+func (d *WebhookDelivery) ScheduleRetry(at time.Time) {
+	d.Status = WEBHOOK_DELIVERY_RETRYING
+	d.NextAttemptAt = &at
+}
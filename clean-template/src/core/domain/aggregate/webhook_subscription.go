@@ -0,0 +1,97 @@
+package aggregate
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+	"github.com/nanda03dev/go-ms-template/src/helper"
+)
+
+type WebhookSubscription struct {
+	ID          string
+	TenantID    string
+	TargetUrl   string
+	EntityNames []string
+	EventTypes  []string
+	Secret      string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func NewWebhookSubscription(createDTO dto.CreateWebhookSubscriptionDTO) (*WebhookSubscription, error) {
+	if err := validateTargetUrl(createDTO.TargetUrl); err != nil {
+		return nil, err
+	}
+
+	secret := createDTO.Secret
+	if secret == "" {
+		generated, err := helper.GenerateSecret(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = generated
+	}
+
+	return &WebhookSubscription{
+		ID:          helper.Generate16DigitUUID(),
+		TargetUrl:   createDTO.TargetUrl,
+		EntityNames: createDTO.EntityNames,
+		EventTypes:  createDTO.EventTypes,
+		Secret:      secret,
+		Active:      true,
+	}, nil
+}
+
+func UpdateWebhookSubscription(existing *WebhookSubscription, updateDTO dto.UpdateWebhookSubscriptionDTO) (*WebhookSubscription, error) {
+	if err := validateTargetUrl(updateDTO.TargetUrl); err != nil {
+		return nil, err
+	}
+
+	secret := existing.Secret
+	if updateDTO.Secret != "" {
+		secret = updateDTO.Secret
+	}
+
+	return &WebhookSubscription{
+		ID:          existing.ID,
+		TenantID:    existing.TenantID,
+		TargetUrl:   updateDTO.TargetUrl,
+		EntityNames: updateDTO.EntityNames,
+		EventTypes:  updateDTO.EventTypes,
+		Secret:      secret,
+		Active:      updateDTO.Active,
+		CreatedAt:   existing.CreatedAt,
+	}, nil
+}
+
+// Matches reports whether the subscription wants the event. Empty lists match everything.
+func (s *WebhookSubscription) Matches(event common.Event) bool {
+	if !s.Active {
+		return false
+	}
+	// Subscriptions without a tenant receive the events of every tenant
+	if s.TenantID != "" && s.TenantID != event.TenantID {
+		return false
+	}
+	if len(s.EntityNames) > 0 && !slices.Contains(s.EntityNames, string(event.EntityName)) {
+		return false
+	}
+	if len(s.EventTypes) > 0 && !slices.Contains(s.EventTypes, string(event.Type)) {
+		return false
+	}
+	return true
+}
+
+func validateTargetUrl(targetUrl string) error {
+	parsed, err := url.ParseRequestURI(targetUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperror.Validation("invalid webhook target url: %s", targetUrl)
+	}
+	return nil
+}
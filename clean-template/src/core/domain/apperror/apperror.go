@@ -0,0 +1,114 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of application errors. Every *Error wraps exactly one, test for them with errors.Is,
+// e.g. errors.Is(err, apperror.ErrNotFound).
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrTimeout      = errors.New("timeout")
+	ErrInternal     = errors.New("internal error")
+)
+
+// Stable error codes, returned to clients in the "code" member of problem responses
+const (
+	CODE_NOT_FOUND       = "NOT_FOUND"
+	CODE_CONFLICT        = "CONFLICT"
+	CODE_VALIDATION      = "VALIDATION_FAILED"
+	CODE_UNAUTHORIZED    = "UNAUTHORIZED"
+	CODE_FORBIDDEN       = "FORBIDDEN"
+	CODE_TIMEOUT         = "TIMEOUT"
+	CODE_INTERNAL        = "INTERNAL_ERROR"
+	CODE_TENANT_REQUIRED = "TENANT_REQUIRED"
+)
+
+// Error is an application error. Its Message is safe to return to clients,
+// the Cause is only logged.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Kind, e.Cause}
+	}
+	return []error{e.Kind}
+}
+
+// Wrap returns a copy of the error caused by cause
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
+
+// WithCode returns a copy of the error with a more specific code than the one of its kind
+func (e *Error) WithCode(code string) *Error {
+	coded := *e
+	coded.Code = code
+	return &coded
+}
+
+func NotFound(format string, args ...any) *Error {
+	return newError(ErrNotFound, CODE_NOT_FOUND, format, args...)
+}
+
+func Conflict(format string, args ...any) *Error {
+	return newError(ErrConflict, CODE_CONFLICT, format, args...)
+}
+
+func Validation(format string, args ...any) *Error {
+	return newError(ErrValidation, CODE_VALIDATION, format, args...)
+}
+
+func Unauthorized(format string, args ...any) *Error {
+	return newError(ErrUnauthorized, CODE_UNAUTHORIZED, format, args...)
+}
+
+func Forbidden(format string, args ...any) *Error {
+	return newError(ErrForbidden, CODE_FORBIDDEN, format, args...)
+}
+
+// Timeout reports work abandoned because its context was cancelled or ran past its deadline
+func Timeout(cause error) *Error {
+	return newError(ErrTimeout, CODE_TIMEOUT, "the request took too long and was cancelled").Wrap(cause)
+}
+
+// Internal hides cause from clients behind a generic message
+func Internal(cause error) *Error {
+	return newError(ErrInternal, CODE_INTERNAL, "internal error").Wrap(cause)
+}
+
+// From returns err as an *Error. Errors of no kind are internal errors.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+func newError(kind error, code string, format string, args ...any) *Error {
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+	return &Error{Kind: kind, Code: code, Message: message}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+)
+
+// APIKey is a stored key. Only the SHA-256 hash of the key is kept, see HashAPIKey.
+type APIKey struct {
+	Name     string   `json:"name"`
+	Hash     string   `json:"hash"`
+	TenantID string   `json:"tenant_id"`
+	Roles    []string `json:"roles"`
+	Scopes   []string `json:"scopes"`
+}
+
+type APIKeyStore struct {
+	keys map[string]APIKey // keyed by hash
+}
+
+// LoadAPIKeys reads a JSON list of APIKey, e.g.
+//
+//	[{"name": "billing", "hash": "<sha256 hex>", "tenant_id": "acme", "roles": ["admin"], "scopes": ["order:read"]}]
+//
+// An empty path gives an empty store.
+func LoadAPIKeys(path string) (*APIKeyStore, error) {
+	store := &APIKeyStore{keys: map[string]APIKey{}}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %w", path, err)
+	}
+
+	for _, key := range keys {
+		key.Hash = strings.ToLower(key.Hash)
+		if _, err := hex.DecodeString(key.Hash); err != nil || len(key.Hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("API key %q: hash must be a hex encoded SHA-256", key.Name)
+		}
+		store.keys[key.Hash] = key
+	}
+
+	return store, nil
+}
+
+func (s *APIKeyStore) Len() int {
+	return len(s.keys)
+}
+
+// Verify looks up the hash of a presented key.
+func (s *APIKeyStore) Verify(presented string) (*common.Principal, error) {
+	hash := HashAPIKey(presented)
+
+	key, ok := s.keys[hash]
+	if !ok || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) != 1 {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	return &common.Principal{
+		Subject:  key.Name,
+		Method:   common.AUTH_METHOD_API_KEY,
+		TenantID: key.TenantID,
+		Roles:    key.Roles,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Settings are loaded by the config package, from the auth section of the config file or the environment:
+//
+//	AUTH_DISABLED=true                    every request is let through as an anonymous principal
+//	AUTH_JWT_HS256_SECRET=<secret>        accept HS256 tokens signed with this secret
+//	AUTH_JWT_RS256_PUBLIC_KEY_FILE=<pem>  accept RS256 tokens signed by this public key
+//	AUTH_JWKS_FILE=<json>                 accept RS256 tokens signed by a key of this JWKS, matched on kid
+//	AUTH_JWT_ISSUER / AUTH_JWT_AUDIENCE   required iss / aud claims, when set
+//	AUTH_JWT_ROLES_CLAIM                  claim holding the caller's roles (default "roles")
+//	AUTH_JWT_TENANT_CLAIM                 claim binding the caller to a tenant (default "tenant_id")
+//	AUTH_API_KEYS_FILE=<json>             hashed API keys, see LoadAPIKeys
+type Settings struct {
+	Disabled       bool   `yaml:"disabled" env:"AUTH_DISABLED"`
+	HS256Secret    string `yaml:"jwt_hs256_secret" env:"AUTH_JWT_HS256_SECRET"`
+	RS256PublicKey string `yaml:"jwt_rs256_public_key_file" env:"AUTH_JWT_RS256_PUBLIC_KEY_FILE"`
+	JWKSFile       string `yaml:"jwks_file" env:"AUTH_JWKS_FILE"`
+	Issuer         string `yaml:"jwt_issuer" env:"AUTH_JWT_ISSUER"`
+	Audience       string `yaml:"jwt_audience" env:"AUTH_JWT_AUDIENCE"`
+	RolesClaim     string `yaml:"jwt_roles_claim" env:"AUTH_JWT_ROLES_CLAIM"`
+	TenantClaim    string `yaml:"jwt_tenant_claim" env:"AUTH_JWT_TENANT_CLAIM"`
+	APIKeysFile    string `yaml:"api_keys_file" env:"AUTH_API_KEYS_FILE"`
+}
+
+type Authenticator struct {
+	Settings Settings
+	JWT      *JWTVerifier
+	APIKeys  *APIKeyStore
+}
+
+var (
+	once          sync.Once
+	authenticator *Authenticator
+	settings      Settings
+)
+
+// Configure sets the settings GetAuthenticator loads the authenticator with
+func Configure(authSettings Settings) {
+	settings = authSettings
+}
+
+func NewAuthenticator(settings Settings) (*Authenticator, error) {
+	jwtVerifier, err := NewJWTVerifier(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	apiKeys, err := LoadAPIKeys(settings.APIKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	return &Authenticator{Settings: settings, JWT: jwtVerifier, APIKeys: apiKeys}, nil
+}
+
+// GetAuthenticator loads the authenticator from the configured settings once; a broken key file stops the service.
+func GetAuthenticator() *Authenticator {
+	once.Do(func() {
+		var err error
+		authenticator, err = NewAuthenticator(settings)
+		if err != nil {
+			slog.Error("authentication setup failed", "error", err)
+			os.Exit(1)
+		}
+
+		switch {
+		case authenticator.Settings.Disabled:
+			slog.Warn("authentication is disabled (AUTH_DISABLED), all routes are public")
+		case !authenticator.JWT.Enabled() && authenticator.APIKeys.Len() == 0:
+			slog.Warn("no JWT keys or API keys are configured, authenticated routes will reject every request")
+		}
+	})
+	return authenticator
+}
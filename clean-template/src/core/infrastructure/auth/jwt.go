@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nanda03dev/go-ms-template/src/common"
+)
+
+// JWTVerifier validates bearer tokens. Only the algorithms a key is configured for are
+// accepted, so an RS256 public key can never be used as an HS256 secret.
+type JWTVerifier struct {
+	hmacSecret  []byte
+	rsaKeys     map[string]*rsa.PublicKey // keyed by kid, "" for the static public key
+	issuer      string
+	audience    string
+	rolesClaim  string
+	tenantClaim string
+}
+
+func NewJWTVerifier(settings Settings) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{
+		rsaKeys:     map[string]*rsa.PublicKey{},
+		issuer:      settings.Issuer,
+		audience:    settings.Audience,
+		rolesClaim:  settings.RolesClaim,
+		tenantClaim: settings.TenantClaim,
+	}
+
+	if settings.HS256Secret != "" {
+		verifier.hmacSecret = []byte(settings.HS256Secret)
+	}
+
+	if settings.RS256PublicKey != "" {
+		pem, err := os.ReadFile(settings.RS256PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", settings.RS256PublicKey, err)
+		}
+		verifier.rsaKeys[""] = key
+	}
+
+	if settings.JWKSFile != "" {
+		keys, err := loadJWKS(settings.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			verifier.rsaKeys[kid] = key
+		}
+	}
+
+	return verifier, nil
+}
+
+func (v *JWTVerifier) Enabled() bool {
+	return v.hmacSecret != nil || len(v.rsaKeys) > 0
+}
+
+// Verify parses a token and maps its claims onto a Principal: sub is the subject,
+// the roles claim the roles, the tenant claim the tenant and "scope" (space separated) or "scp" the scopes.
+func (v *JWTVerifier) Verify(token string) (*common.Principal, error) {
+	if !v.Enabled() {
+		return nil, fmt.Errorf("JWT authentication is not configured")
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(v.validMethods()), jwt.WithExpirationRequired()}
+	if v.issuer != "" {
+		options = append(options, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		options = append(options, jwt.WithAudience(v.audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.keyFunc, options...); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims.GetSubject()
+	tenantId, _ := claims[v.tenantClaim].(string)
+
+	return &common.Principal{
+		Subject:  subject,
+		Method:   common.AUTH_METHOD_JWT,
+		TenantID: tenantId,
+		Roles:    stringList(claims[v.rolesClaim]),
+		Scopes:   scopesOf(claims),
+		Claims:   claims,
+	}, nil
+}
+
+func (v *JWTVerifier) validMethods() []string {
+	var methods []string
+	if v.hmacSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	return methods
+}
+
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.hmacSecret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if key, ok := v.rsaKeys[""]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS reads the RSA signing keys of a JSON Web Key Set file
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %q: %w", key.Kid, err)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s has no RSA signing keys", path)
+	}
+
+	return keys, nil
+}
+
+func scopesOf(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	return stringList(claims["scp"])
+}
+
+func stringList(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var result []string
+		for _, each := range value {
+			if s, ok := each.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
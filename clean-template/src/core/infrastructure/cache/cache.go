@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Backends of an entity cache
+const (
+	BACKEND_MEMORY = "memory" // an LRU per replica, writes on other replicas show after the TTL
+	BACKEND_REDIS  = "redis"  // shared by every replica
+)
+
+// Store holds cached values by key
+type Store interface {
+	// Get reports false when key is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increments the counter at key and returns its new value, counters do not expire
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Settings of the cache of one entity, set from the "cache" block of gStructify.config.json.
+// The zero value disables the cache.
+type Settings struct {
+	Backend    string
+	TTL        time.Duration
+	MaxEntries int  // of the memory backend
+	Filters    bool // also cache FindWithFilter results
+}
+
+func (s Settings) Enabled() bool {
+	return s.Backend != ""
+}
+
+// Config of the cache backends, loaded by the config package
+type Config struct {
+	RedisURL string `yaml:"redis_url" env:"REDIS_URL"`
+	// KeyPrefix keeps the keys of services sharing a Redis apart
+	KeyPrefix string `yaml:"key_prefix" env:"CACHE_KEY_PREFIX"`
+}
+
+var (
+	config      Config
+	redisMutex  sync.Mutex
+	redisClient *redis.Client
+)
+
+// Configure sets the backend settings, it must be called before NewStore
+func Configure(cacheConfig Config) {
+	config = cacheConfig
+}
+
+// NewStore returns the store of an entity cache. Memory stores are private to the entity,
+// the Redis client is shared.
+func NewStore(settings Settings) (Store, error) {
+	switch settings.Backend {
+	case BACKEND_MEMORY:
+		return NewMemoryStore(settings.MaxEntries), nil
+	case BACKEND_REDIS:
+		client, err := getRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return &redisStore{client: client, prefix: config.KeyPrefix}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q, expected %s or %s", settings.Backend, BACKEND_MEMORY, BACKEND_REDIS)
+	}
+}
+
+func getRedisClient() (*redis.Client, error) {
+	redisMutex.Lock()
+	defer redisMutex.Unlock()
+
+	if redisClient != nil {
+		return redisClient, nil
+	}
+	if config.RedisURL == "" {
+		return nil, fmt.Errorf("the redis cache backend needs REDIS_URL")
+	}
+
+	options, err := redis.ParseURL(config.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	redisClient = redis.NewClient(options)
+	slog.Info("connected to Redis cache", "address", options.Addr)
+	return redisClient, nil
+}
+
+// Close closes the Redis client, if any
+func Close() {
+	redisMutex.Lock()
+	defer redisMutex.Unlock()
+
+	if redisClient == nil {
+		return
+	}
+	if err := redisClient.Close(); err != nil {
+		slog.Error("failed to close Redis client", "error", err)
+	}
+	redisClient = nil
+}
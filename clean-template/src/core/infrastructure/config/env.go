@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadEnv sets every field of target tagged with `env:"NAME"` from the environment variable NAME.
+// Nested structs are walked, unset and empty variables keep the current value.
+func loadEnv(target any) error {
+	return loadEnvStruct(reflect.ValueOf(target).Elem())
+}
+
+func loadEnvStruct(value reflect.Value) error {
+	var errs []error
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		name, tagged := value.Type().Field(i).Tag.Lookup("env")
+		if !tagged {
+			if field.Kind() == reflect.Struct {
+				errs = append(errs, loadEnvStruct(field))
+			}
+			continue
+		}
+
+		raw := os.Getenv(name)
+		if raw == "" {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q: %w", name, raw, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setField(field reflect.Value, raw string) error {
+	// e.g. slog.Level
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		// Comma separated, e.g. SQL_DB_REPLICA_URIS=postgres://a,postgres://b
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
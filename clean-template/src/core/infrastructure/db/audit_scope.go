@@ -0,0 +1,87 @@
+package db
+
+import (
+	"reflect"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CreatedByColumn = "created_by"
+	UpdatedByColumn = "updated_by"
+	DeletedByColumn = "deleted_by"
+)
+
+// RegisterAuditScope fills the audit columns of audited models with the actor of the statement's
+// context (common.ActorFromContext). Like updated_at, updated_by is left alone by UpdateColumn(s).
+func RegisterAuditScope(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().Before("gorm:create").After("tenant:create").Register("audit:create", auditCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").After("tenant:update").Register("audit:update", auditUpdate); err != nil {
+		return err
+	}
+	return callback.Delete().Before("gorm:delete").After("tenant:delete").Register("audit:delete", auditDelete)
+}
+
+func auditCreate(db *gorm.DB) {
+	if db.Error != nil || !isAudited(db.Statement) {
+		return
+	}
+
+	if actor := common.ActorFromContext(db.Statement.Context); actor != "" {
+		db.Statement.SetColumn(CreatedByColumn, actor, true)
+		db.Statement.SetColumn(UpdatedByColumn, actor, true)
+	}
+}
+
+func auditUpdate(db *gorm.DB) {
+	if db.Error != nil || !isAudited(db.Statement) {
+		return
+	}
+
+	values, isMap := db.Statement.Dest.(map[string]any)
+	if !isMap {
+		// Full record updates do not carry who created or deleted the record, keep the stored values
+		db.Statement.Omits = append(db.Statement.Omits, CreatedByColumn, DeletedByColumn)
+	} else if deletedAt, ok := values["deleted_at"]; ok && deletedAt == nil {
+		// A restore clears who deleted the record
+		db.Statement.SetColumn(DeletedByColumn, "", true)
+	}
+
+	if actor := common.ActorFromContext(db.Statement.Context); actor != "" && !db.Statement.SkipHooks {
+		db.Statement.SetColumn(UpdatedByColumn, actor, true)
+	}
+}
+
+// auditDelete stores who deletes before a soft delete, which itself only sets deleted_at
+func auditDelete(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Unscoped || !isAudited(db.Statement) {
+		return
+	}
+
+	actor := common.ActorFromContext(db.Statement.Context)
+	where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	if actor == "" || !ok || len(where.Exprs) == 0 {
+		return
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table).
+		Clauses(where).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}, Value: nil})
+	if err := tx.UpdateColumn(DeletedByColumn, actor).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+func isAudited(stmt *gorm.Statement) bool {
+	if stmt.Schema == nil {
+		return false
+	}
+	_, ok := reflect.New(stmt.Schema.ModelType).Interface().(common.AuditedModel)
+	return ok
+}
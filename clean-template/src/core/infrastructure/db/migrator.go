@@ -0,0 +1,468 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"gorm.io/gorm"
+)
+
+// Directories of the migrations tree, see Migrator
+const (
+	MIGRATIONS_DIR = "sql-migrations" // at the root of the service, embedded in the binary
+	SQL_DIR        = "sql"
+	SEED_DIR       = "seed"
+)
+
+// Statuses reported by Migrator.Status
+const (
+	MIGRATION_APPLIED  = "applied"
+	MIGRATION_PENDING  = "pending"
+	MIGRATION_MODIFIED = "modified" // applied, then its up script was edited
+	MIGRATION_MISSING  = "missing"  // applied, but its files are gone
+)
+
+// migrationLockKey is the advisory lock serializing migrations across replicas
+const migrationLockKey = "migrations"
+
+// noTransactionDirective on the first line of a script runs it outside a transaction, for
+// statements Postgres refuses in one, like CREATE INDEX CONCURRENTLY
+const noTransactionDirective = "-- migrate:no-transaction"
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+type MigrationMetadata struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"unique;not null"`
+	Checksum   string    // sha256 of the up script, empty for migrations applied before checksums
+	ExecutedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Migration is a script of sql/, as a <version>_<name>.up.sql and <version>_<name>.down.sql
+// pair, or of seed/, which is never rolled back. Migrations run in the order of their names.
+type Migration struct {
+	Name string // e.g. 20240101120000_create_users, or the file name of single file scripts
+	Seed bool
+	Up   string
+	Down string // empty when the migration cannot be rolled back
+}
+
+func (m Migration) checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+type MigrationStatus struct {
+	Name      string
+	Status    string
+	AppliedAt time.Time
+}
+
+// Migrator applies the migrations of files, the sql-migrations tree, to the database. Every run
+// holds a Postgres advisory lock, so replicas starting together migrate one after the other.
+type Migrator struct {
+	sqlDB *SqlDB
+	files fs.FS
+}
+
+func NewMigrator(sqlDB *SqlDB, files fs.FS) *Migrator {
+	return &Migrator{sqlDB: sqlDB, files: files}
+}
+
+// Up migrates the models, then applies up to steps pending migrations of sql/, every one
+// when steps is 0, then the pending seeds. It refuses to run when an applied migration was edited
+// or is missing.
+func (m *Migrator) Up(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		if err := migrateModels(db); err != nil {
+			return err
+		}
+
+		migrations, applied, err := m.load(db)
+		if err != nil {
+			return err
+		}
+		if err := verifyChecksums(migrations, applied); err != nil {
+			return err
+		}
+		if err := verifyPresent(migrations, applied); err != nil {
+			return err
+		}
+
+		var pending []Migration
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Name]; !ok {
+				pending = append(pending, migration)
+			}
+		}
+
+		// Seeds come last and only run once every migration is applied, they may need all of them
+		count, skipped := 0, false
+		for _, migration := range pending {
+			if migration.Seed && skipped {
+				break
+			}
+			if !migration.Seed && steps > 0 && count == steps {
+				skipped = true
+				continue
+			}
+			if err := m.apply(db, migration, migration.Up, true); err != nil {
+				return err
+			}
+			count++
+		}
+
+		if len(pending) == 0 {
+			slog.Info("database is up to date")
+		}
+		return nil
+	})
+}
+
+// Down rolls back the last steps applied migrations of sql/, seeds are never rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		migrations, applied, err := m.load(db)
+		if err != nil {
+			return err
+		}
+
+		rollbacks, err := lastApplied(migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+		if len(rollbacks) == 0 {
+			slog.Info("no migration to roll back")
+		}
+
+		for _, migration := range rollbacks {
+			if err := m.apply(db, migration, migration.Down, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Redo rolls back the last applied migration of sql/ and applies it again, to iterate on a
+// migration in development
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(db *gorm.DB) error {
+		migrations, applied, err := m.load(db)
+		if err != nil {
+			return err
+		}
+
+		rollbacks, err := lastApplied(migrations, applied, 1)
+		if err != nil {
+			return err
+		}
+		if len(rollbacks) == 0 {
+			return errors.New("no migration to redo")
+		}
+
+		migration := rollbacks[0]
+		if err := m.apply(db, migration, migration.Down, false); err != nil {
+			return err
+		}
+		return m.apply(db, migration, migration.Up, true)
+	})
+}
+
+// Status lists every migration, applied or not, in the order they run
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db := m.sqlDB.DB.WithContext(ctx)
+	if err := db.AutoMigrate(&MigrationMetadata{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate migration metadata: %w", err)
+	}
+
+	migrations, applied, err := m.load(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		metadata, ok := applied[migration.Name]
+		switch {
+		case !ok:
+			statuses = append(statuses, MigrationStatus{Name: migration.Name, Status: MIGRATION_PENDING})
+		case metadata.Checksum != "" && metadata.Checksum != migration.checksum():
+			statuses = append(statuses, MigrationStatus{Name: migration.Name, Status: MIGRATION_MODIFIED, AppliedAt: metadata.ExecutedAt})
+		default:
+			statuses = append(statuses, MigrationStatus{Name: migration.Name, Status: MIGRATION_APPLIED, AppliedAt: metadata.ExecutedAt})
+		}
+		delete(applied, migration.Name)
+	}
+	var missing []MigrationStatus
+	for _, metadata := range applied {
+		missing = append(missing, MigrationStatus{Name: metadata.Name, Status: MIGRATION_MISSING, AppliedAt: metadata.ExecutedAt})
+	}
+	slices.SortFunc(missing, func(a, b MigrationStatus) int { return strings.Compare(a.Name, b.Name) })
+	return append(statuses, missing...), nil
+}
+
+// CreateMigration writes an empty up and down script named after the current time to dir, the
+// sql/ directory of the migrations tree, and returns their paths
+func CreateMigration(dir string, name string) (string, string, error) {
+	if !migrationNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("migration name %q must be snake_case", name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	base := filepath.Join(dir, time.Now().UTC().Format("20060102150405")+"_"+name)
+	upPath, downPath := base+".up.sql", base+".down.sql"
+	if err := os.WriteFile(upPath, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- Revert "+name+"\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+// withLock runs fn while holding the migration lock, waiting for it when another process is
+// migrating
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	unlock, err := m.sqlDB.AdvisoryLock(ctx, migrationLockKey)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	db := m.sqlDB.DB.WithContext(ctx)
+	if err := db.AutoMigrate(&MigrationMetadata{}); err != nil {
+		return fmt.Errorf("failed to migrate migration metadata: %w", err)
+	}
+	return fn(db)
+}
+
+// apply runs script and records (up) or forgets (down) migration, in one transaction unless the
+// script opts out
+func (m *Migrator) apply(db *gorm.DB, migration Migration, script string, up bool) error {
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+
+	record := func(tx *gorm.DB) error {
+		if up {
+			return tx.Create(&MigrationMetadata{Name: migration.Name, Checksum: migration.checksum()}).Error
+		}
+		return tx.Where("name = ?", migration.Name).Delete(&MigrationMetadata{}).Error
+	}
+	run := func(tx *gorm.DB) error {
+		if !isBlankScript(script) {
+			if err := tx.Exec(script).Error; err != nil {
+				return fmt.Errorf("failed to run migration %s %s: %w", migration.Name, direction, err)
+			}
+		}
+		if err := record(tx); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(script), noTransactionDirective) {
+		err = run(db)
+	} else {
+		err = db.Transaction(run)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("ran migration", "migration", migration.Name, "direction", direction, "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// load reads the migrations of the tree and the applied ones, by name
+func (m *Migrator) load(db *gorm.DB) ([]Migration, map[string]MigrationMetadata, error) {
+	migrations, err := readMigrations(m.files, SQL_DIR, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	seeds, err := readMigrations(m.files, SEED_DIR, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrations = append(migrations, seeds...)
+
+	var rows []MigrationMetadata
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[string]MigrationMetadata, len(rows))
+	for _, row := range rows {
+		applied[row.Name] = row
+	}
+	return migrations, applied, nil
+}
+
+// readMigrations reads the scripts of dir sorted by name. A file without an .up.sql or .down.sql
+// suffix is an up script without a down script. Only seeds may be missing altogether.
+func readMigrations(files fs.FS, dir string, seed bool) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if errors.Is(err, fs.ErrNotExist) && seed {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations of %s: %w", dir, err)
+	}
+
+	byName := map[string]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+
+		content, err := fs.ReadFile(files, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", fileName, err)
+		}
+
+		name, down := fileName, false
+		if base, ok := strings.CutSuffix(fileName, ".down.sql"); ok {
+			name, down = base, true
+		} else if base, ok := strings.CutSuffix(fileName, ".up.sql"); ok {
+			name = base
+		}
+
+		migration, ok := byName[name]
+		if !ok {
+			migration = &Migration{Name: name, Seed: seed}
+			byName[name] = migration
+		}
+		if down {
+			migration.Down = string(content)
+		} else {
+			migration.Up = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byName))
+	for _, migration := range byName {
+		if migration.Up == "" && migration.Down != "" {
+			return nil, fmt.Errorf("migration %s has a down script but no up script", migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return strings.Compare(a.Name, b.Name) })
+	return migrations, nil
+}
+
+// verifyChecksums fails when the up script of an applied migration changed, the database would
+// silently differ from what the script says otherwise
+func verifyChecksums(migrations []Migration, applied map[string]MigrationMetadata) error {
+	var errs []error
+	for _, migration := range migrations {
+		metadata, ok := applied[migration.Name]
+		if ok && metadata.Checksum != "" && metadata.Checksum != migration.checksum() {
+			errs = append(errs, fmt.Errorf("migration %s was edited after it was applied, add a new migration instead", migration.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verifyPresent fails when applied migrations are not in the tree, which usually means the tree
+// the migrations are read from is not the one of this service, or is outdated
+func verifyPresent(migrations []Migration, applied map[string]MigrationMetadata) error {
+	known := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Name] = true
+	}
+
+	var missing []string
+	for name := range applied {
+		if !known[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("applied migrations are missing from the migrations tree: %s", strings.Join(missing, ", "))
+}
+
+// lastApplied returns the last steps applied migrations of sql/, latest first
+func lastApplied(migrations []Migration, applied map[string]MigrationMetadata, steps int) ([]Migration, error) {
+	known := map[string]Migration{}
+	for _, migration := range migrations {
+		known[migration.Name] = migration
+	}
+
+	var rows []MigrationMetadata
+	for name, metadata := range applied {
+		if migration, ok := known[name]; ok && migration.Seed {
+			continue
+		}
+		rows = append(rows, metadata)
+	}
+	// In the order they were applied, which differs from the order of names after merging branches
+	slices.SortFunc(rows, func(a, b MigrationMetadata) int { return int(b.ID) - int(a.ID) })
+
+	var rollbacks []Migration
+	for _, row := range rows {
+		if len(rollbacks) == steps {
+			break
+		}
+		migration, ok := known[row.Name]
+		if !ok {
+			return nil, fmt.Errorf("migration %s is applied but its files are missing", row.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %s has no down script", row.Name)
+		}
+		rollbacks = append(rollbacks, migration)
+	}
+	return rollbacks, nil
+}
+
+// SQLMigratedModel is implemented by the models whose table is created and altered by the
+// migrations gStructify generates in sql/
+type SQLMigratedModel interface {
+	SQLMigrated()
+}
+
+// migrateModels creates and extends the tables of the other entities with GORM AutoMigrate
+func migrateModels(db *gorm.DB) error {
+	for _, model := range entity.Entities {
+		if _, ok := model.(SQLMigratedModel); ok {
+			continue
+		}
+		if err := db.AutoMigrate(model); err != nil {
+			return fmt.Errorf("failed to migrate %T: %w", model, err)
+		}
+	}
+	return nil
+}
+
+// isBlankScript reports whether script holds nothing but comments and whitespace
+func isBlankScript(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
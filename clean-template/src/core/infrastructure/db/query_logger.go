@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// queryLogger routes GORM logs through the logger of the statement's context, so queries are
+// logged with the request id, tenant and worker they run for.
+// Every query is logged at debug level, queries slower than slowThreshold at warn level.
+type queryLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewQueryLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &queryLogger{level: gormlogger.Info, slowThreshold: slowThreshold}
+}
+
+func (l *queryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *queryLogger) Info(ctx context.Context, message string, data ...any) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).InfoContext(ctx, fmt.Sprintf(message, data...))
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, message string, data ...any) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).WarnContext(ctx, fmt.Sprintf(message, data...))
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, message string, data ...any) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(message, data...))
+	}
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := logger.FromContext(ctx)
+
+	switch {
+	// A missing record is reported to the caller, it is not a failure of the query
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.ErrorContext(ctx, "query failed", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds(), "error", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds(), "threshold_ms", l.slowThreshold.Milliseconds())
+	case l.level >= gormlogger.Info && log.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		log.DebugContext(ctx, "query", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	}
+}
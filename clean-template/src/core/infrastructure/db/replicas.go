@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+	"gorm.io/gorm"
+)
+
+// replicaPingTimeout bounds the health check of a replica
+const replicaPingTimeout = 2 * time.Second
+
+// Replicas are the read replicas of the primary. Reads are spread over the healthy ones
+// round-robin; a replica failing its ping is skipped until it answers again.
+type Replicas struct {
+	replicas []*replica
+	next     atomic.Uint64
+	stop     chan struct{}
+	wait     sync.WaitGroup
+}
+
+type replica struct {
+	name    string
+	sqlDB   *SqlDB
+	healthy atomic.Bool
+}
+
+// ConnectReplicas opens a pool per replica. Replicas do not run migrations and an unreachable
+// replica does not stop the service, it is only used once it answers a ping.
+func ConnectReplicas(uris []string, checkInterval time.Duration) (*Replicas, error) {
+	replicas := &Replicas{stop: make(chan struct{})}
+	for index, uri := range uris {
+		sqlDB := &SqlDB{}
+		if err := sqlDB.connect(uri, true); err != nil {
+			replicas.Disconnect()
+			return nil, fmt.Errorf("replica %d: %w", index+1, err)
+		}
+		name := fmt.Sprintf("replica-%d", index+1)
+		sqlDB.setConfigs(name)
+		replicas.replicas = append(replicas.replicas, &replica{name: name, sqlDB: sqlDB})
+	}
+
+	// Replicas start healthy so the first check logs the ones that are not
+	for _, replica := range replicas.replicas {
+		replica.healthy.Store(true)
+	}
+	replicas.check(context.Background())
+	replicas.wait.Add(1)
+	go replicas.monitor(checkInterval)
+	return replicas, nil
+}
+
+// pick returns the next healthy replica, nil when none is healthy
+func (r *Replicas) pick() *SqlDB {
+	count := uint64(len(r.replicas))
+	if count == 0 {
+		return nil
+	}
+
+	start := r.next.Add(1)
+	for offset := uint64(0); offset < count; offset++ {
+		replica := r.replicas[(start+offset)%count]
+		if replica.healthy.Load() {
+			return replica.sqlDB
+		}
+	}
+	return nil
+}
+
+func (r *Replicas) monitor(interval time.Duration) {
+	defer r.wait.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.check(context.Background())
+		}
+	}
+}
+
+// check pings every replica and updates its health, logging changes
+func (r *Replicas) check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := replica.sqlDB.Ping(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("replica is healthy, routing reads to it", "replica", replica.name)
+			} else {
+				slog.Warn("replica is unhealthy, routing reads away from it", "replica", replica.name, "error", err)
+			}
+		}
+
+		up := 0.0
+		if healthy {
+			up = 1
+		}
+		metrics.DBReplicaUp.WithLabelValues(replica.name).Set(up)
+	}
+}
+
+// Disconnect stops the health checks and closes the replica pools
+func (r *Replicas) Disconnect() {
+	close(r.stop)
+	r.wait.Wait()
+	for _, replica := range r.replicas {
+		replica.sqlDB.Disconnect()
+	}
+}
+
+type primaryContextKey struct{}
+
+// WithPrimary sends the reads made with ctx to the primary, so they see the writes just made
+// ("read your writes") instead of a replica that may lag behind.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func UsesPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
+
+// Reader returns the database reads made with ctx should use: a healthy replica, or the primary
+// when there is none or ctx asks for it with WithPrimary.
+func (d *Databases) Reader(ctx context.Context) *gorm.DB {
+	if d.Replicas == nil || UsesPrimary(ctx) {
+		return d.SqlDB.DB
+	}
+	if replica := d.Replicas.pick(); replica != nil {
+		return replica.DB
+	}
+	return d.SqlDB.DB
+}
@@ -1,13 +1,13 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"io"
+	"hash/fnv"
 	"log"
-	"os"
-	"time"
+	"log/slog"
 
-	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -16,12 +16,7 @@ type SqlDB struct {
 	DB *gorm.DB
 }
 
-type MigrationMetadata struct {
-	ID         uint      `gorm:"primaryKey"`
-	Name       string    `gorm:"unique;not null"`
-	ExecutedAt time.Time `gorm:"autoCreateTime"`
-}
-
+// ConnectSqlDB opens the pool of the primary database. It does not migrate it, see Migrator.
 func ConnectSqlDB(uri string) (*SqlDB, error) {
 	sqlDB := &SqlDB{}
 	err := sqlDB.Connect(uri)
@@ -29,32 +24,40 @@ func ConnectSqlDB(uri string) (*SqlDB, error) {
 		return nil, err
 	}
 
-	// Run model migrations before starting the application
-	if err := RunModelMigration(sqlDB.DB); err != nil {
-		log.Fatalf("Could not run model migrations: %v", err)
-	}
-
-	// Run raw migrations before starting the application
-	if err := RunRawMigration(sqlDB.DB); err != nil {
-		log.Fatalf("Could not run raw migrations: %v", err)
-	}
-
-	// Run raw migrations before starting the application
-	if err := RunSeedMigration(sqlDB.DB); err != nil {
-		log.Fatalf("Could not run seed migrations: %v", err)
-	}
-
 	// Set connection configs
-	sqlDB.setConfigs()
+	sqlDB.setConfigs("primary")
 
 	return sqlDB, nil
 }
 
 func (p *SqlDB) Connect(uri string) error {
-	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{})
+	return p.connect(uri, false)
+}
+
+// connect opens the pool of uri, without checking the database is reachable when lazy
+func (p *SqlDB) connect(uri string, lazy bool) error {
+	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{
+		// Unique and foreign key violations are reported as gorm.ErrDuplicatedKey and gorm.ErrForeignKeyViolated
+		TranslateError:       true,
+		Logger:               NewQueryLogger(settings.SlowQueryThreshold),
+		DisableAutomaticPing: lazy,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to SQL Database: %w", err)
 	}
+	if err := RegisterTenantScope(db, settings.TenantIsolation); err != nil {
+		return fmt.Errorf("failed to register tenant scope: %w", err)
+	}
+	if err := RegisterAuditScope(db); err != nil {
+		return fmt.Errorf("failed to register audit scope: %w", err)
+	}
+	if err := RegisterQueryMetrics(db); err != nil {
+		return fmt.Errorf("failed to register query metrics: %w", err)
+	}
+	if err := RegisterQueryTracing(db); err != nil {
+		return fmt.Errorf("failed to register query tracing: %w", err)
+	}
+
 	p.DB = db
 	return nil
 }
@@ -62,131 +65,87 @@ func (p *SqlDB) Connect(uri string) error {
 func (p *SqlDB) Disconnect() {
 	db, err := p.DB.DB()
 	if err != nil {
-		log.Printf("Error getting raw database connection: %v", err)
+		slog.Error("failed to get raw database connection", "error", err)
 		return
 	}
 	db.Close()
 }
 
-func (p *SqlDB) Health() (string, bool) {
+// Ping checks the database is reachable, it backs the "sql" readiness check
+func (p *SqlDB) Ping(ctx context.Context) error {
 	db, err := p.DB.DB()
 	if err != nil {
-		return "Failed to get raw database connection", false
+		return fmt.Errorf("failed to get raw database connection: %w", err)
 	}
+	return db.PingContext(ctx)
+}
 
-	db.SetConnMaxLifetime(2 * time.Second)
+// TryAdvisoryLock takes a session level Postgres advisory lock identified by key without waiting.
+// The lock lives on a dedicated connection; the returned unlock function releases both.
+func (p *SqlDB) TryAdvisoryLock(ctx context.Context, key string) (func(), bool, error) {
+	return p.advisoryLock(ctx, key, false)
+}
 
-	err = db.Ping()
-	if err != nil {
-		return "SQL Database health check failed", false
-	} else {
-		return "SQL Database connection is healthy!", true
-	}
+// AdvisoryLock is TryAdvisoryLock waiting for the lock, until ctx is done
+func (p *SqlDB) AdvisoryLock(ctx context.Context, key string) (func(), error) {
+	unlock, _, err := p.advisoryLock(ctx, key, true)
+	return unlock, err
 }
 
-func (p *SqlDB) setConfigs() {
-	// Get the generic database connection object `*sql.DB` to configure it
-	sqlDB, err := p.DB.DB()
+func (p *SqlDB) advisoryLock(ctx context.Context, key string, wait bool) (func(), bool, error) {
+	db, err := p.DB.DB()
 	if err != nil {
-		log.Fatalf("failed to get sql.DB: %v", err)
+		return nil, false, fmt.Errorf("failed to get raw database connection: %w", err)
 	}
 
-	// Set database connection pool parameters
-	sqlDB.SetMaxIdleConns(10)               // Maximum number of idle connections
-	sqlDB.SetMaxOpenConns(100)              // Maximum number of open connections
-	sqlDB.SetConnMaxLifetime(1 * time.Hour) // Maximum time a connection can be reused
-}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection for advisory lock: %w", err)
+	}
 
-func RunModelMigration(db *gorm.DB) error {
-	// AutoMigrate all registered entities
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	lockId := int64(hash.Sum64())
 
-	if err := db.AutoMigrate(&MigrationMetadata{}); err != nil {
-		panic("failed to migrate database")
+	acquired := true
+	if wait {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockId)
+	} else {
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockId).Scan(&acquired)
 	}
-
-	for _, entity := range entity.Entities {
-		if err := db.AutoMigrate(entity); err != nil {
-			panic("failed to migrate database")
-		}
+	if err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to take advisory lock %s: %w", key, err)
 	}
-	return nil
-}
-
-func RunRawMigration(db *gorm.DB) error {
-	path := "/sql-migrations/sql/"
-	return RunRawSQL(db, path)
-}
 
-func RunSeedMigration(db *gorm.DB) error {
-	path := "/sql-migrations/seed/"
-	return RunRawSQL(db, path)
-}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
 
-func RecordMigration(db *gorm.DB, migrationName string) error {
-	metadata := MigrationMetadata{
-		Name: migrationName,
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockId); err != nil {
+			slog.Error("failed to release advisory lock", "key", key, "error", err)
+		}
+		conn.Close()
 	}
-	return db.Create(&metadata).Error
-}
 
-func IsMigrationExecuted(db *gorm.DB, migrationName string) (bool, error) {
-	var count int64
-	err := db.Model(&MigrationMetadata{}).Where("name = ?", migrationName).Count(&count).Error
-	return count > 0, err
+	return unlock, true, nil
 }
 
-func RunRawSQL(db *gorm.DB, path string) error {
-	wd, err := os.Getwd()
+func (p *SqlDB) setConfigs(name string) {
+	// Get the generic database connection object `*sql.DB` to configure it
+	sqlDB, err := p.DB.DB()
 	if err != nil {
-		return fmt.Errorf("error getting current directory: %w", err)
+		log.Fatalf("failed to get sql.DB: %v", err)
 	}
 
-	migrationPath := wd + path
+	// Set database connection pool parameters
+	sqlDB.SetMaxIdleConns(settings.MaxIdleConns)       // Maximum number of idle connections
+	sqlDB.SetMaxOpenConns(settings.MaxOpenConns)       // Maximum number of open connections
+	sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime) // Maximum time a connection can be reused
 
-	files, err := os.ReadDir(migrationPath)
-	if err != nil {
-		// fmt.Println("failed to read migration directory: %w", err)
-		return nil
-	}
-
-	for _, file := range files {
-		if !file.IsDir() {
-			migrationFileName := file.Name()
-
-			executed, err := IsMigrationExecuted(db, migrationFileName)
-			if err != nil {
-				return err
-			}
-
-			if executed {
-				continue
-			}
-
-			// Open the migration file
-			f, err := os.Open(migrationPath + migrationFileName)
-			if err != nil {
-				return fmt.Errorf("failed to open migration file %s: %w", migrationFileName, err)
-			}
-			defer f.Close()
-
-			// Read the content of the migration file
-			script, err := io.ReadAll(f)
-			if err != nil {
-				return fmt.Errorf("failed to read migration file %s: %w", migrationFileName, err)
-			}
-
-			// Execute the migration script
-			if err := db.Exec(string(script)).Error; err != nil {
-				return fmt.Errorf("failed to execute migration %s: %w", migrationFileName, err)
-			}
-
-			// Record the executed migration.
-			if err := RecordMigration(db, migrationFileName); err != nil {
-				return err
-			}
-			log.Printf("Executed migration: %s", file.Name())
-		}
+	if err := metrics.RegisterDBStats(name, sqlDB); err != nil {
+		slog.Error("failed to register database pool metrics", "error", err)
 	}
-
-	return nil
 }
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tenant isolation modes, set with TENANT_ISOLATION
+const (
+	TENANT_ISOLATION_ROW    = "row"    // tenant_id column on shared tables
+	TENANT_ISOLATION_SCHEMA = "schema" // a Postgres schema per tenant, see CreateTenantSchema
+)
+
+const TenantColumn = "tenant_id"
+
+// ErrTenantRequired is returned for statements on tenant scoped models without a tenant
+var ErrTenantRequired = apperror.Validation("a tenant is required, set the X-Tenant-ID header").WithCode(apperror.CODE_TENANT_REQUIRED)
+
+var tenantIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,48}$`)
+
+type tenantMode int
+
+const (
+	tenantModeNone tenantMode = iota
+	tenantModeAware
+	tenantModeScoped
+)
+
+// tenantScope scopes every statement on tenant models to the tenant of the statement's context,
+// so repositories cannot read or write across tenants even when a query forgets a condition.
+type tenantScope struct {
+	isolation string
+}
+
+func RegisterTenantScope(db *gorm.DB, isolation string) error {
+	scope := &tenantScope{isolation: isolation}
+	callback := db.Callback()
+
+	if err := callback.Create().Before("gorm:create").Register("tenant:create", scope.create); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tenant:query", scope.filter); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenant:update", scope.update); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenant:delete", scope.filter); err != nil {
+		return err
+	}
+	return callback.Row().Before("gorm:row").Register("tenant:row", scope.filter)
+}
+
+// ValidateTenantId rejects tenant ids that cannot be used as a schema name suffix
+func ValidateTenantId(tenantId string) error {
+	if !tenantIdPattern.MatchString(tenantId) {
+		return apperror.Validation("invalid tenant id %q, expected 1-48 letters, digits, '_' or '-'", tenantId)
+	}
+	return nil
+}
+
+// RequireTenant refuses reads for a tenant scoped model without a tenant, the way the tenant scope
+// does for its rows. The tenant aware rows kept for the model, like its events, are only narrowed to
+// a tenant when the context has one and must be guarded with it.
+func RequireTenant(ctx context.Context, model any) error {
+	if _, scoped := model.(common.TenantScopedModel); !scoped || common.IsAllTenants(ctx) {
+		return nil
+	}
+	if _, ok := common.TenantFromContext(ctx); !ok {
+		return ErrTenantRequired
+	}
+	return nil
+}
+
+func TenantSchema(tenantId string) string {
+	return "tenant_" + strings.ReplaceAll(strings.ToLower(tenantId), "-", "_")
+}
+
+func (s *tenantScope) create(db *gorm.DB) {
+	mode := tenantModeOf(db.Statement)
+	if mode == tenantModeNone || db.Error != nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	tenantId, ok := common.TenantFromContext(ctx)
+	if !ok {
+		// System operations keep the tenant the rows carry
+		if mode == tenantModeScoped && !common.IsAllTenants(ctx) {
+			db.AddError(ErrTenantRequired.Wrap(fmt.Errorf("create %s", db.Statement.Table)))
+		}
+		return
+	}
+
+	s.useSchema(db, mode, tenantId)
+	// Tenant scoped rows always get the context's tenant, tenant aware rows only when they have none
+	s.stamp(db, tenantId, mode == tenantModeScoped)
+}
+
+func (s *tenantScope) update(db *gorm.DB) {
+	s.filter(db)
+
+	if tenantModeOf(db.Statement) != tenantModeScoped || db.Error != nil {
+		return
+	}
+	// Full row updates must not clear the tenant of the row
+	if tenantId, ok := common.TenantFromContext(db.Statement.Context); ok {
+		s.stamp(db, tenantId, true)
+	}
+}
+
+func (s *tenantScope) filter(db *gorm.DB) {
+	mode := tenantModeOf(db.Statement)
+	if mode == tenantModeNone || db.Error != nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if common.IsAllTenants(ctx) {
+		return
+	}
+
+	tenantId, ok := common.TenantFromContext(ctx)
+	if !ok {
+		if mode == tenantModeScoped {
+			db.AddError(ErrTenantRequired.Wrap(fmt.Errorf("query %s", db.Statement.Table)))
+		}
+		return
+	}
+
+	s.useSchema(db, mode, tenantId)
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: TenantColumn}, Value: tenantId},
+	}})
+}
+
+// useSchema points statements on tenant scoped tables to the tenant's schema in schema isolation
+func (s *tenantScope) useSchema(db *gorm.DB, mode tenantMode, tenantId string) {
+	if s.isolation != TENANT_ISOLATION_SCHEMA || mode != tenantModeScoped || strings.Contains(db.Statement.Table, ".") {
+		return
+	}
+	db.Statement.Table = TenantSchema(tenantId) + "." + db.Statement.Table
+}
+
+func (s *tenantScope) stamp(db *gorm.DB, tenantId string, overwrite bool) {
+	field := db.Statement.Schema.LookUpField(TenantColumn)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	set := func(value reflect.Value) {
+		value = reflect.Indirect(value)
+		if value.Kind() != reflect.Struct {
+			return
+		}
+		if _, isZero := field.ValueOf(ctx, value); isZero || overwrite {
+			if err := field.Set(ctx, value, tenantId); err != nil {
+				db.AddError(err)
+			}
+		}
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < value.Len(); index++ {
+			set(value.Index(index))
+		}
+	case reflect.Struct:
+		set(value)
+	}
+}
+
+func tenantModeOf(stmt *gorm.Statement) tenantMode {
+	if stmt.Schema == nil {
+		return tenantModeNone
+	}
+
+	switch reflect.New(stmt.Schema.ModelType).Interface().(type) {
+	case common.TenantScopedModel:
+		return tenantModeScoped
+	case common.TenantAwareModel:
+		return tenantModeAware
+	default:
+		return tenantModeNone
+	}
+}
+
+// CheckSchemaIsolation refuses schema isolation for tenant scoped models migrated by the sql/
+// migrations. Those create the tables of the public schema only, the schema of a tenant would get
+// tables that never follow them.
+func CheckSchemaIsolation() error {
+	var models []string
+	for _, model := range entity.Entities {
+		_, scoped := model.(common.TenantScopedModel)
+		_, migrated := model.(SQLMigratedModel)
+		if scoped && migrated {
+			models = append(models, reflect.Indirect(reflect.ValueOf(model)).Type().Name())
+		}
+	}
+	if len(models) > 0 {
+		return fmt.Errorf("TENANT_ISOLATION=%s does not support the multi tenant entities migrated by sql-migrations/sql (%s), use TENANT_ISOLATION=%s",
+			TENANT_ISOLATION_SCHEMA, strings.Join(models, ", "), TENANT_ISOLATION_ROW)
+	}
+	return nil
+}
+
+// CreateTenantSchema creates the schema of a tenant and migrates the tenant scoped tables into it.
+// It is only needed with schema isolation.
+func (p *SqlDB) CreateTenantSchema(tenantId string) error {
+	if err := ValidateTenantId(tenantId); err != nil {
+		return err
+	}
+	if err := CheckSchemaIsolation(); err != nil {
+		return err
+	}
+
+	schema := TenantSchema(tenantId)
+	if err := p.DB.Exec("CREATE SCHEMA IF NOT EXISTS ?", clause.Table{Name: schema}).Error; err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+
+	for _, model := range entity.Entities {
+		if _, ok := model.(common.TenantScopedModel); !ok {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: p.DB}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		table := schema + "." + stmt.Schema.Table
+		if err := p.DB.Table(table).AutoMigrate(model); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
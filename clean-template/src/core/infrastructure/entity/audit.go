@@ -0,0 +1,11 @@
+package entity
+
+// AuditModel is embedded in the models of entities with audit fields ("audit_fields" in gStructify.config.json).
+// The columns are set from the actor of the statement's context, see db.RegisterAuditScope.
+type AuditModel struct {
+	CreatedBy string `gorm:"not null;default:''"`
+	UpdatedBy string `gorm:"not null;default:''"`
+	DeletedBy string `gorm:"not null;default:''"`
+}
+
+func (AuditModel) Audited() {}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"gorm.io/gorm"
+)
+
+const DeadLetterEventEntityName common.EntityName = "DeadLetterEvent"
+
+type DeadLetterEvent struct {
+	gorm.Model
+	ID         string `gorm:"primaryKey"`
+	EventId    string `gorm:"index"`
+	EntityId   string
+	EntityName string
+	Type       string
+	Channel    string `gorm:"index"`
+	Payload    string
+	Error      string
+	Attempts   int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Helper function: Converts an aggregate DeadLetterEvent to an entity DeadLetterEvent
+func NewDeadLetterEvent(deadLetterEvent *aggregate.DeadLetterEvent) *DeadLetterEvent {
+	return &DeadLetterEvent{
+		ID:         deadLetterEvent.ID,
+		EventId:    deadLetterEvent.EventId,
+		EntityId:   deadLetterEvent.EntityId,
+		EntityName: deadLetterEvent.EntityName,
+		Type:       deadLetterEvent.Type,
+		Channel:    deadLetterEvent.Channel,
+		Payload:    deadLetterEvent.Payload,
+		Error:      deadLetterEvent.Error,
+		Attempts:   deadLetterEvent.Attempts,
+		CreatedAt:  deadLetterEvent.CreatedAt,
+		UpdatedAt:  deadLetterEvent.UpdatedAt,
+	}
+}
+
+func (e *DeadLetterEvent) GetEntityName() common.EntityName {
+	return DeadLetterEventEntityName
+}
+
+// Helper function: Converts an entity DeadLetterEvent to an aggregate DeadLetterEvent
+func (e *DeadLetterEvent) ToDomain() *aggregate.DeadLetterEvent {
+	return &aggregate.DeadLetterEvent{
+		ID:         e.ID,
+		EventId:    e.EventId,
+		EntityId:   e.EntityId,
+		EntityName: e.EntityName,
+		Type:       e.Type,
+		Channel:    e.Channel,
+		Payload:    e.Payload,
+		Error:      e.Error,
+		Attempts:   e.Attempts,
+		CreatedAt:  e.CreatedAt,
+		UpdatedAt:  e.UpdatedAt,
+	}
+}
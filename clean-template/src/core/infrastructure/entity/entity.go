@@ -3,4 +3,7 @@ package entity
 var Entities = []interface{}{
 	&TemplateEntity{},
 	&Event{},
+	&DeadLetterEvent{},
+	&WebhookSubscription{},
+	&WebhookDelivery{},
 }
@@ -15,7 +15,10 @@ type Event struct {
 	ID         string `gorm:"primaryKey"`
 	EntityId   string
 	EntityName string
+	TenantID   string `gorm:"index"`
+	Actor      string
 	Type       string
+	Data       string `gorm:"type:text"`
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -26,7 +29,10 @@ func NewEvent(event *aggregate.Event) *Event {
 		ID:         event.ID,
 		EntityId:   event.EntityId,
 		EntityName: event.EntityName,
+		TenantID:   event.TenantID,
+		Actor:      event.Actor,
 		Type:       event.Type,
+		Data:       event.Data,
 		CreatedAt:  event.CreatedAt,
 		UpdatedAt:  event.UpdatedAt,
 	}
@@ -36,13 +42,19 @@ func (e *Event) GetEntityName() common.EntityName {
 	return EventEntityName
 }
 
+// Events are shared by all tenants, reads are scoped when the context has a tenant
+func (e *Event) TenantAware() {}
+
 // Helper function: Converts an entity Event to an aggregate Event
 func (e *Event) ToDomain() *aggregate.Event {
 	return &aggregate.Event{
 		ID:         e.ID,
 		EntityId:   e.EntityId,
 		EntityName: e.EntityName,
+		TenantID:   e.TenantID,
+		Actor:      e.Actor,
 		Type:       e.Type,
+		Data:       e.Data,
 		CreatedAt:  e.CreatedAt,
 		UpdatedAt:  e.UpdatedAt,
 	}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
@@ -10,8 +11,16 @@ import (
 
 const TemplateEntityEntityName common.EntityName = "TemplateEntity"
 
+// TemplateEntityConfig controls how templateEntity events are stored, set from gStructify.config.json
+var TemplateEntityConfig = common.EntityConfig{
+	EventStore:   true,
+	EventSourced: EVENT_SOURCED,
+}
+
 type TemplateEntity struct {
 	gorm.Model
+	TENANT_MODEL
+	AUDIT_MODEL
 	ID        string `gorm:"primaryKey"`
 	#@$Field$ $FieldType$#@
 	CreatedAt time.Time
@@ -32,25 +41,35 @@ func (e *TemplateEntity) GetEntityName() common.EntityName {
 	return TemplateEntityEntityName
 }
 
+// SQLMigrated marks the templateEntity table as created by the generated SQL migrations, see db.SQLMigratedModel
+func (e *TemplateEntity) SQLMigrated() {}
+
 func (e *TemplateEntity) GetCreatedEvent() common.Event {
 	return e.GetEvent(common.ENTITY_CREATED)
 }
 func (e *TemplateEntity) GetUpdatedEvent() common.Event {
-	return e.GetEvent(common.ENTITY_CREATED)
+	return e.GetEvent(common.ENTITY_UPDATED)
 }
 func (e *TemplateEntity) GetDeletedEvent() common.Event {
-	return e.GetEvent(common.ENTITY_CREATED)
+	return e.GetEvent(common.ENTITY_DELETED)
+}
+func (e *TemplateEntity) GetRestoredEvent() common.Event {
+	return e.GetEvent(common.ENTITY_RESTORED)
 }
 
 func (e *TemplateEntity) GetEvent(operationType common.EventType) common.Event {
+	// The snapshot lets the state of the record be rebuilt from its events
+	data, _ := json.Marshal(e.ToDomain())
+
 	return common.Event{
 		ID:         helper.Generate16DigitUUID(),
 		EntityId:   e.ID,
 		EntityName: e.GetEntityName(),
+		TenantID:   TenantIdOf(e),
 		Type:       operationType,
-		Config: common.EntityConfig{
-			EventStore: true,
-		},
+		OccurredAt: time.Now(),
+		Data:       string(data),
+		Config:     TemplateEntityConfig,
 	}
 }
 
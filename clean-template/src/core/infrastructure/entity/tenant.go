@@ -0,0 +1,21 @@
+package entity
+
+// TenantModel is embedded in the models of multi tenant entities ("multi_tenant" in gStructify.config.json).
+// The tenant_id column is set and filtered by the tenant scope of the database, see db.RegisterTenantScope.
+type TenantModel struct {
+	TenantID string `gorm:"index;not null;default:''"`
+}
+
+func (TenantModel) TenantScoped() {}
+
+func (m TenantModel) GetTenantID() string {
+	return m.TenantID
+}
+
+// TenantIdOf returns the tenant of a multi tenant model, empty for other models
+func TenantIdOf(model any) string {
+	if tenantModel, ok := model.(interface{ GetTenantID() string }); ok {
+		return tenantModel.GetTenantID()
+	}
+	return ""
+}
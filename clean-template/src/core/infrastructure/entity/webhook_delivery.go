@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"gorm.io/gorm"
+)
+
+const WebhookDeliveryEntityName common.EntityName = "WebhookDelivery"
+
+type WebhookDelivery struct {
+	gorm.Model
+	ID             string `gorm:"primaryKey"`
+	TenantID       string `gorm:"index"`
+	SubscriptionId string `gorm:"index"`
+	EventId        string `gorm:"index"`
+	EventType      string
+	Payload        string
+	Status         string `gorm:"index"`
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  *time.Time `gorm:"index"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Helper function: Converts an aggregate WebhookDelivery to an entity WebhookDelivery
+func NewWebhookDelivery(webhookDelivery *aggregate.WebhookDelivery) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:             webhookDelivery.ID,
+		TenantID:       webhookDelivery.TenantID,
+		SubscriptionId: webhookDelivery.SubscriptionId,
+		EventId:        webhookDelivery.EventId,
+		EventType:      webhookDelivery.EventType,
+		Payload:        webhookDelivery.Payload,
+		Status:         string(webhookDelivery.Status),
+		Attempts:       webhookDelivery.Attempts,
+		ResponseStatus: webhookDelivery.ResponseStatus,
+		LastError:      webhookDelivery.LastError,
+		NextAttemptAt:  webhookDelivery.NextAttemptAt,
+		DeliveredAt:    webhookDelivery.DeliveredAt,
+		CreatedAt:      webhookDelivery.CreatedAt,
+		UpdatedAt:      webhookDelivery.UpdatedAt,
+	}
+}
+
+func (e *WebhookDelivery) GetEntityName() common.EntityName {
+	return WebhookDeliveryEntityName
+}
+
+func (e *WebhookDelivery) TenantAware() {}
+
+// Helper function: Converts an entity WebhookDelivery to an aggregate WebhookDelivery
+func (e *WebhookDelivery) ToDomain() *aggregate.WebhookDelivery {
+	return &aggregate.WebhookDelivery{
+		ID:             e.ID,
+		TenantID:       e.TenantID,
+		SubscriptionId: e.SubscriptionId,
+		EventId:        e.EventId,
+		EventType:      e.EventType,
+		Payload:        e.Payload,
+		Status:         aggregate.WebhookDeliveryStatus(e.Status),
+		Attempts:       e.Attempts,
+		ResponseStatus: e.ResponseStatus,
+		LastError:      e.LastError,
+		NextAttemptAt:  e.NextAttemptAt,
+		DeliveredAt:    e.DeliveredAt,
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+	}
+}
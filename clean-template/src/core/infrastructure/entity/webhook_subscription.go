@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"gorm.io/gorm"
+)
+
+const WebhookSubscriptionEntityName common.EntityName = "WebhookSubscription"
+
+type WebhookSubscription struct {
+	gorm.Model
+	ID          string   `gorm:"primaryKey"`
+	TenantID    string   `gorm:"index"`
+	TargetUrl   string   `gorm:"not null"`
+	EntityNames []string `gorm:"serializer:json"`
+	EventTypes  []string `gorm:"serializer:json"`
+	Secret      string   `gorm:"not null"`
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Helper function: Converts an aggregate WebhookSubscription to an entity WebhookSubscription
+func NewWebhookSubscription(webhookSubscription *aggregate.WebhookSubscription) *WebhookSubscription {
+	return &WebhookSubscription{
+		ID:          webhookSubscription.ID,
+		TenantID:    webhookSubscription.TenantID,
+		TargetUrl:   webhookSubscription.TargetUrl,
+		EntityNames: webhookSubscription.EntityNames,
+		EventTypes:  webhookSubscription.EventTypes,
+		Secret:      webhookSubscription.Secret,
+		Active:      webhookSubscription.Active,
+		CreatedAt:   webhookSubscription.CreatedAt,
+		UpdatedAt:   webhookSubscription.UpdatedAt,
+	}
+}
+
+func (e *WebhookSubscription) GetEntityName() common.EntityName {
+	return WebhookSubscriptionEntityName
+}
+
+// Subscriptions created for a tenant only receive and show that tenant's events
+func (e *WebhookSubscription) TenantAware() {}
+
+// Helper function: Converts an entity WebhookSubscription to an aggregate WebhookSubscription
+func (e *WebhookSubscription) ToDomain() *aggregate.WebhookSubscription {
+	return &aggregate.WebhookSubscription{
+		ID:          e.ID,
+		TenantID:    e.TenantID,
+		TargetUrl:   e.TargetUrl,
+		EntityNames: e.EntityNames,
+		EventTypes:  e.EventTypes,
+		Secret:      e.Secret,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
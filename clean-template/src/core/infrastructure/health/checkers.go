@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ChannelSaturation fails once channel is filled beyond threshold, a fraction of its capacity.
+// Events are dropped when it is full, so the process stops taking traffic before it is.
+func ChannelSaturation[T any](name string, channel chan T, threshold float64) Checker {
+	return NewChecker(name, func(context.Context) error {
+		if cap(channel) == 0 {
+			return nil
+		}
+		saturation := float64(len(channel)) / float64(cap(channel))
+		if saturation > threshold {
+			return fmt.Errorf("%d of %d slots used", len(channel), cap(channel))
+		}
+		return nil
+	})
+}
+
+// Heartbeat tracks the progress of a worker. A worker waiting for work is idle and always
+// healthy; a busy worker fails the check when it has not made progress for maxAge.
+type Heartbeat struct {
+	name   string
+	maxAge time.Duration
+
+	mutex    sync.Mutex
+	lastBeat time.Time
+	idle     bool
+}
+
+func NewHeartbeat(name string, maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{name: name, maxAge: maxAge, lastBeat: time.Now(), idle: true}
+}
+
+// Beat records progress and marks the worker busy
+func (h *Heartbeat) Beat() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.lastBeat = time.Now()
+	h.idle = false
+}
+
+// Idle marks the worker as waiting for work
+func (h *Heartbeat) Idle() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.lastBeat = time.Now()
+	h.idle = true
+}
+
+func (h *Heartbeat) Name() string {
+	return h.name
+}
+
+func (h *Heartbeat) Check(context.Context) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if !h.idle && time.Since(h.lastBeat) > h.maxAge {
+		return fmt.Errorf("no progress for %s", time.Since(h.lastBeat).Round(time.Millisecond))
+	}
+	return nil
+}
+
+type heartbeatContextKey struct{}
+
+// WithHeartbeat carries the heartbeat of a worker, so the code it calls can report progress
+func WithHeartbeat(ctx context.Context, heartbeat *Heartbeat) context.Context {
+	return context.WithValue(ctx, heartbeatContextKey{}, heartbeat)
+}
+
+// Beat records progress on the heartbeat of ctx, if any
+func Beat(ctx context.Context) {
+	if heartbeat, ok := ctx.Value(heartbeatContextKey{}).(*Heartbeat); ok {
+		heartbeat.Beat()
+	}
+}
+
+// Idle marks the worker of ctx, if any, as waiting for work
+func Idle(ctx context.Context) {
+	if heartbeat, ok := ctx.Value(heartbeatContextKey{}).(*Heartbeat); ok {
+		heartbeat.Idle()
+	}
+}
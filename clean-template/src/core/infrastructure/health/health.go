@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	STATUS_UP   = "up"
+	STATUS_DOWN = "down"
+)
+
+// CheckTimeout bounds every check, a check still running after it is reported down
+const CheckTimeout = 2 * time.Second
+
+// Checker reports whether one dependency of the service works
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// NewChecker turns a function into a Checker, e.g. health.NewChecker("sql", sqlDB.Ping)
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r Report) Up() bool {
+	return r.Status == STATUS_UP
+}
+
+var (
+	checkersMutex     sync.Mutex
+	livenessCheckers  []Checker
+	readinessCheckers []Checker
+	shuttingDown      atomic.Bool
+)
+
+// RegisterLiveness adds checks to /livez. A failing liveness check gets the process restarted,
+// so only register checks the process cannot recover from by itself, like a stuck worker.
+func RegisterLiveness(checkers ...Checker) {
+	checkersMutex.Lock()
+	defer checkersMutex.Unlock()
+	livenessCheckers = append(livenessCheckers, checkers...)
+}
+
+// RegisterReadiness adds checks to /readyz. A failing readiness check stops traffic to the
+// process until it passes again, e.g. while its database is unreachable.
+func RegisterReadiness(checkers ...Checker) {
+	checkersMutex.Lock()
+	defer checkersMutex.Unlock()
+	readinessCheckers = append(readinessCheckers, checkers...)
+}
+
+// SetShuttingDown fails readiness from now on, so no new traffic is routed to a process
+// that is shutting down
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+func Liveness(ctx context.Context) Report {
+	checkersMutex.Lock()
+	checkers := append([]Checker(nil), livenessCheckers...)
+	checkersMutex.Unlock()
+
+	return run(ctx, checkers)
+}
+
+func Readiness(ctx context.Context) Report {
+	checkersMutex.Lock()
+	checkers := append([]Checker(nil), readinessCheckers...)
+	checkersMutex.Unlock()
+
+	checkers = append(checkers, NewChecker("shutdown", func(context.Context) error {
+		if shuttingDown.Load() {
+			return errors.New("shutting down")
+		}
+		return nil
+	}))
+	return run(ctx, checkers)
+}
+
+// run runs the checks concurrently, each bounded by CheckTimeout
+func run(ctx context.Context, checkers []Checker) Report {
+	report := Report{Status: STATUS_UP, Checks: make(map[string]CheckResult, len(checkers))}
+
+	var mutex sync.Mutex
+	var wait sync.WaitGroup
+	for _, checker := range checkers {
+		wait.Add(1)
+		go func(checker Checker) {
+			defer wait.Done()
+			result := runCheck(ctx, checker)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[checker.Name()] = result
+			if result.Status != STATUS_UP {
+				report.Status = STATUS_DOWN
+			}
+		}(checker)
+	}
+	wait.Wait()
+
+	return report
+}
+
+func runCheck(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Checks that ignore their context must not hang the probe
+		err = fmt.Errorf("timed out after %s", CheckTimeout)
+	}
+
+	result := CheckResult{Status: STATUS_UP, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = STATUS_DOWN
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	LOG_FORMAT_JSON = "json"
+	LOG_FORMAT_TEXT = "text"
+)
+
+// Settings of the application logger, loaded by the config package
+type Settings struct {
+	Level  slog.Level `yaml:"level" env:"LOG_LEVEL"`
+	Format string     `yaml:"format" env:"LOG_FORMAT"`
+}
+
+func init() {
+	// Logs written before the configuration is loaded use the defaults
+	// slog.SetDefault also routes the standard log package through the logger
+	slog.SetDefault(New(Settings{Level: slog.LevelInfo, Format: LOG_FORMAT_JSON}, os.Stdout))
+}
+
+func New(settings Settings, writer io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: settings.Level}
+	if settings.Format == LOG_FORMAT_TEXT {
+		return slog.New(slog.NewTextHandler(writer, options))
+	}
+	return slog.New(slog.NewJSONHandler(writer, options))
+}
+
+type loggerContextKey struct{}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger when it has none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With returns a context whose logger adds args, e.g. logger.With(ctx, "tenant_id", tenantId),
+// to every record logged through it.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
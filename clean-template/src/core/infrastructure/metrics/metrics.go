@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry holds every metric of the service, it is served on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HttpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of GORM statements by table, operation and outcome.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"table", "operation", "status"})
+
+	DBReplicaUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "db_replica_up",
+		Help: "Whether a read replica answers its health check and receives reads (1) or not (0).",
+	}, []string{"replica"})
+
+	WorkerEventsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "worker_events_processed_total",
+		Help: "Events handled successfully by background workers.",
+	}, []string{"worker"})
+
+	WorkerEventsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "worker_events_failed_total",
+		Help: "Events background workers gave up on after retrying.",
+	}, []string{"worker"})
+
+	ChannelEventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "channel_events_dropped_total",
+		Help: "Events dropped because their worker channel was full.",
+	}, []string{"channel"})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "Entity cache lookups by entity, query and result (hit, miss or error).",
+	}, []string{"entity", "query", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequestDuration,
+		HttpRequestsTotal,
+		DBQueryDuration,
+		DBReplicaUp,
+		WorkerEventsProcessed,
+		WorkerEventsFailed,
+		ChannelEventsDropped,
+		CacheRequests,
+	)
+}
+
+// RegisterChannel exposes the number of events queued on a worker channel and its capacity
+func RegisterChannel[T any](name string, channel chan T) {
+	labels := prometheus.Labels{"channel": name}
+	Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "channel_depth",
+			Help:        "Events queued on a worker channel.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(len(channel)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "channel_capacity",
+			Help:        "Capacity of a worker channel.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(cap(channel)) }),
+	)
+}
+
+// RegisterDBStats exposes the connection pool statistics of db, see sql.DB.Stats
+func RegisterDBStats(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -19,38 +27,91 @@ func NewBaseRepository[T any](db *gorm.DB) *BaseRepository[T] {
 	return &BaseRepository[T]{db: db}
 }
 
+// WithContext returns the repository bound to ctx. Statements on tenant models are scoped
+// to the tenant of ctx, see db.RegisterTenantScope.
+func (r *BaseRepository[T]) WithContext(ctx context.Context) *BaseRepository[T] {
+	return NewBaseRepository[T](r.db.WithContext(ctx))
+}
+
+// readerOf returns a repository bound to ctx on the database reads should use, a replica unless
+// ctx asks for the primary, see db.Databases.Reader
+func readerOf[T any](ctx context.Context, databases *db.Databases) *BaseRepository[T] {
+	return NewBaseRepository[T](databases.Reader(ctx)).WithContext(ctx)
+}
+
+// startSpan starts the span of a repository call. Statements run through the returned repository
+// are children of the span.
+func (r *BaseRepository[T]) startSpan(operation string) (*BaseRepository[T], trace.Span) {
+	ctx, span := tracing.Start(r.db.Statement.Context, "BaseRepository."+operation,
+		trace.WithAttributes(attribute.String("entity", reflect.TypeOf(new(T)).Elem().Name())),
+	)
+	return NewBaseRepository[T](r.db.WithContext(ctx)), span
+}
+
+// Transaction runs fn inside a database transaction. The repository passed to fn is bound
+// to the transaction; the transaction is committed when fn returns nil.
+func (r *BaseRepository[T]) Transaction(fn func(txRepo *BaseRepository[T]) error) (err error) {
+	r, span := r.startSpan("Transaction")
+	defer func() { tracing.End(span, err) }()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewBaseRepository[T](tx))
+	})
+}
+
 // Create inserts a new record into the database.
-func (r *BaseRepository[T]) Create(entity *T) (*T, error) {
+func (r *BaseRepository[T]) Create(entity *T) (_ *T, err error) {
+	r, span := r.startSpan("Create")
+	defer func() { tracing.End(span, err) }()
+
 	if err := r.db.Create(entity).Error; err != nil {
-		return nil, fmt.Errorf("failed to create record: %w", err)
+		return nil, dbError(err, "create record")
 	}
 
 	return entity, nil
 }
 
 // Bulk inserts a new record into the database.
-func (r *BaseRepository[T]) BulkCreate(entities []*T) ([]*T, error) {
+func (r *BaseRepository[T]) BulkCreate(entities []*T) (_ []*T, err error) {
+	r, span := r.startSpan("BulkCreate")
+	defer func() { tracing.End(span, err) }()
+
 	if err := r.db.Create(entities).Error; err != nil {
-		return nil, fmt.Errorf("failed to create record: %w", err)
+		return nil, dbError(err, "create records")
 	}
 
 	return entities, nil
 }
 
 // FindById retrieves a record by its ID.
-func (r *BaseRepository[T]) FindById(id string) (*T, error) {
+func (r *BaseRepository[T]) FindById(id string) (_ *T, err error) {
+	r, span := r.startSpan("FindById")
+	defer func() { tracing.End(span, err) }()
+
 	var entity T
 	if err := r.db.First(&entity, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("record not found")
-		}
-		return nil, fmt.Errorf("failed to find record by ID: %w", err)
+		return nil, dbError(err, "find record by ID")
+	}
+	return &entity, nil
+}
+
+// FindDeletedById retrieves a soft deleted record by its ID.
+func (r *BaseRepository[T]) FindDeletedById(id string) (_ *T, err error) {
+	r, span := r.startSpan("FindDeletedById")
+	defer func() { tracing.End(span, err) }()
+
+	var entity T
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&entity, "id = ?", id).Error; err != nil {
+		return nil, dbError(err, "find deleted record by ID")
 	}
 	return &entity, nil
 }
 
 // FindById retrieves a record by its ID.
-func (r *BaseRepository[T]) FindByIdWithRelation(id string, preloadRelations []string) (*T, error) {
+func (r *BaseRepository[T]) FindByIdWithRelation(id string, preloadRelations []string) (_ *T, err error) {
+	r, span := r.startSpan("FindByIdWithRelation")
+	defer func() { tracing.End(span, err) }()
+
 	var entity T
 	query := r.db.Model(&entity)
 
@@ -62,7 +123,7 @@ func (r *BaseRepository[T]) FindByIdWithRelation(id string, preloadRelations []s
 	// Now execute the First query to fetch the entity with preloaded relations
 	if err := query.First(&entity, "id = ?", id).Error; err != nil {
 		// Handle error (e.g., record not found, or other DB errors)
-		return nil, fmt.Errorf("failed to fetch entity with relations: %w", err)
+		return nil, dbError(err, "fetch entity with relations")
 	}
 
 	// Return the entity with preloaded relations
@@ -70,28 +131,41 @@ func (r *BaseRepository[T]) FindByIdWithRelation(id string, preloadRelations []s
 }
 
 // FindWithFilter retrieves records based on filters, sorting, limit, and skip for pagination
-func (r *BaseRepository[T]) FindWithFilter(filterQuery common.FilterQuery) ([]*T, error) {
+func (r *BaseRepository[T]) FindWithFilter(filterQuery common.FilterQuery) (_ []*T, err error) {
+	r, span := r.startSpan("FindWithFilter")
+	defer func() { tracing.End(span, err) }()
+
 	var results []*T
 
 	// Fetch valid columns for the table corresponding to model T
 	validColumns, err := GetValidColumnsForTable[T](r.db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve valid columns for table: %w", err)
+		return nil, apperror.Internal(fmt.Errorf("failed to retrieve valid columns for table: %w", err))
 	}
 
 	// Apply filters
 	query := r.db.Model(new(T))
+
+	for _, restriction := range filterQuery.Restrictions {
+		if !validColumns[restriction.Key] {
+			return nil, apperror.Validation("invalid column name: %s", restriction.Key)
+		}
+		query = query.Where(fmt.Sprintf("%s = ?", restriction.Key), restriction.Value)
+	}
+
+	// Conditions are grouped, so OR logic cannot widen the restrictions
+	conditions := r.db.Session(&gorm.Session{NewDB: true}).Model(new(T))
 	// Determine whether to use AND or OR
-	operationFunc := query.Where // Default to AND
+	operationFunc := conditions.Where // Default to AND
 	if strings.ToUpper(filterQuery.Logic) == "OR" {
-		operationFunc = query.Or
+		operationFunc = conditions.Or
 	}
 
 	// Loop through filters and dynamically apply conditions
 	for _, filter := range filterQuery.Conditions {
 
 		if !validColumns[filter.Key] {
-			return nil, fmt.Errorf("invalid column name: %s", filter.Key)
+			return nil, apperror.Validation("invalid column name: %s", filter.Key)
 		}
 
 		var condition string
@@ -114,7 +188,11 @@ func (r *BaseRepository[T]) FindWithFilter(filterQuery common.FilterQuery) ([]*T
 			condition = fmt.Sprintf("%s = ?", filter.Key)
 		}
 
-		query = operationFunc(condition, filter.Value)
+		conditions = operationFunc(condition, filter.Value)
+	}
+
+	if len(filterQuery.Conditions) > 0 {
+		query = query.Where(conditions)
 	}
 
 	// Apply sorting
@@ -131,7 +209,7 @@ func (r *BaseRepository[T]) FindWithFilter(filterQuery common.FilterQuery) ([]*T
 				Desc:   true,
 			})
 		} else {
-			return nil, fmt.Errorf("invalid order type %s", order.Type)
+			return nil, apperror.Validation("invalid order type %s", order.Type)
 		}
 	}
 
@@ -148,29 +226,79 @@ func (r *BaseRepository[T]) FindWithFilter(filterQuery common.FilterQuery) ([]*T
 
 	// Execute the query
 	if err := query.Find(&results).Error; err != nil {
-		return nil, fmt.Errorf("failed to find records with filters: %w", err)
+		return nil, dbError(err, "find records with filters")
 	}
 
 	return results, nil
 }
 
-// Update modifies an existing record in the database.
-func (r *BaseRepository[T]) Update(entity *T) (*T, error) {
-	if err := r.db.Save(entity).Error; err != nil {
-		return nil, fmt.Errorf("failed to update record: %w", err)
+// Update modifies all columns of an existing record in the database. Unlike Save it never
+// inserts, so a record outside the repository's tenant cannot be overwritten.
+func (r *BaseRepository[T]) Update(entity *T) (_ *T, err error) {
+	r, span := r.startSpan("Update")
+	defer func() { tracing.End(span, err) }()
+
+	result := r.db.Model(entity).Select("*").Updates(entity)
+	if result.Error != nil {
+		return nil, dbError(result.Error, "update record")
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperror.NotFound(common.RecordNotFoundError)
 	}
 	return entity, nil
 }
 
 // Delete removes a record by its ID.
-func (r *BaseRepository[T]) Delete(id string) error {
+func (r *BaseRepository[T]) Delete(id string) (err error) {
+	r, span := r.startSpan("Delete")
+	defer func() { tracing.End(span, err) }()
+
 	var entity T
-	if err := r.db.Delete(&entity, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete record: %w", err)
+	result := r.db.Delete(&entity, "id = ?", id)
+	if result.Error != nil {
+		return dbError(result.Error, "delete record")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(common.RecordNotFoundError)
+	}
+	return nil
+}
+
+// Restore brings back a soft deleted record by its ID.
+func (r *BaseRepository[T]) Restore(id string) (err error) {
+	r, span := r.startSpan("Restore")
+	defer func() { tracing.End(span, err) }()
+
+	result := r.db.Unscoped().Model(new(T)).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return dbError(result.Error, "restore record")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound(common.RecordNotFoundError)
 	}
 	return nil
 }
 
+// dbError maps a database error onto an application error. Errors that already are
+// application errors, e.g. from the tenant scope, are kept.
+func dbError(err error, action string) error {
+	var appErr *apperror.Error
+	switch {
+	case errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NotFound(common.RecordNotFoundError)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.Conflict(common.RecordExistsError).Wrap(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperror.Conflict(common.RecordReferencedError).Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return apperror.Timeout(fmt.Errorf("failed to %s: %w", action, err))
+	default:
+		return apperror.Internal(fmt.Errorf("failed to %s: %w", action, err))
+	}
+}
+
 // getValidColumns retrieves valid columns for the specific table associated with the model
 func GetValidColumnsForTable[T any](db *gorm.DB) (map[string]bool, error) {
 	// Parse the model to get its schema
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+)
+
+type DeadLetterEventRepository interface {
+	Create(ctx context.Context, deadLetterEvent *aggregate.DeadLetterEvent) (*aggregate.DeadLetterEvent, error)
+	FindById(ctx context.Context, id string) (*aggregate.DeadLetterEvent, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.DeadLetterEvent, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// deadLetterEventRepository implements the DeadLetterEventRepository interface.
+type deadLetterEventRepository struct {
+	*BaseRepository[entity.DeadLetterEvent] // Embeds BaseRepository for CRUD operations
+}
+
+// NewDeadLetterEventRepository initializes a new deadLetterEventRepository instance.
+func NewDeadLetterEventRepository(databases *db.Databases) DeadLetterEventRepository {
+	return &deadLetterEventRepository{
+		BaseRepository: NewBaseRepository[entity.DeadLetterEvent](databases.SqlDB.DB),
+	}
+}
+
+// Create inserts a new dead letter event.
+func (r *deadLetterEventRepository) Create(ctx context.Context, deadLetterEvent *aggregate.DeadLetterEvent) (*aggregate.DeadLetterEvent, error) {
+	created, err := r.BaseRepository.WithContext(ctx).Create(entity.NewDeadLetterEvent(deadLetterEvent))
+	if err != nil {
+		return nil, err
+	}
+	return created.ToDomain(), nil
+}
+
+// FindById retrieves a dead letter event by its ID.
+func (r *deadLetterEventRepository) FindById(ctx context.Context, id string) (*aggregate.DeadLetterEvent, error) {
+	deadLetterEvent, err := r.BaseRepository.WithContext(ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	return deadLetterEvent.ToDomain(), nil
+}
+
+// FindWithFilter retrieves dead letter events matching the filter.
+func (r *deadLetterEventRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.DeadLetterEvent, error) {
+	deadLetterEvents, err := r.BaseRepository.WithContext(ctx).FindWithFilter(filterQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dead letter events: %w", err)
+	}
+
+	var result []*aggregate.DeadLetterEvent
+	for _, deadLetterEvent := range deadLetterEvents {
+		result = append(result, deadLetterEvent.ToDomain())
+	}
+
+	return result, nil
+}
+
+// Delete removes a dead letter event by its ID.
+func (r *deadLetterEventRepository) Delete(ctx context.Context, id string) error {
+	return r.BaseRepository.WithContext(ctx).Delete(id)
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/cache"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	cacheQueryFindById = "find_by_id"
+	cacheQueryFilter   = "filter"
+)
+
+// entityCache caches the reads of one entity. Keys hold the tenant of the read so a tenant never
+// sees the records of another; errors and missing records are never cached. Concurrent misses of
+// the same key share a single database query. Every write bumps the entity's generation, a value
+// loaded while it changed may be stale and is not kept.
+type entityCache[A any] struct {
+	name         common.EntityName
+	store        cache.Store
+	settings     cache.Settings
+	tenantScoped bool
+	group        singleflight.Group
+}
+
+func newEntityCache[A any](name common.EntityName, settings cache.Settings, tenantScoped bool) (*entityCache[A], error) {
+	store, err := cache.NewStore(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	entityCache := &entityCache[A]{name: name, store: store, settings: settings, tenantScoped: tenantScoped}
+	registerCacheInvalidator(name, entityCache.invalidate)
+	return entityCache, nil
+}
+
+// findById returns the cached record of id, or loads and caches it
+func (c *entityCache[A]) findById(ctx context.Context, id string, load func() (*A, error)) (*A, error) {
+	return getOrLoad(ctx, c, cacheQueryFindById, c.idKey(c.tenantOf(ctx), id), load)
+}
+
+// findWithFilter returns the cached result of filterQuery, or loads and caches it. Filters are
+// invalidated together by bumping the entity's filter version on every write.
+func (c *entityCache[A]) findWithFilter(ctx context.Context, filterQuery common.FilterQuery, load func() ([]*A, error)) ([]*A, error) {
+	if !c.settings.Filters {
+		return load()
+	}
+
+	version, err := c.filterVersion(ctx)
+	if err != nil {
+		metrics.CacheRequests.WithLabelValues(string(c.name), cacheQueryFilter, "error").Inc()
+		logger.FromContext(ctx).Warn("cache unavailable, reading from the database", "entity", c.name, "error", err)
+		return load()
+	}
+
+	// Restrictions are not serialized with the query, they are part of the key all the same
+	hash, err := json.Marshal(struct {
+		Query        common.FilterQuery
+		Restrictions []common.Condition
+	}{filterQuery, filterQuery.Restrictions})
+	if err != nil {
+		return load()
+	}
+	sum := sha256.Sum256(hash)
+	key := fmt.Sprintf("%s:%s:filter:%d:%s", c.name, c.tenantOf(ctx), version, hex.EncodeToString(sum[:]))
+
+	loaded, err := getOrLoad(ctx, c, cacheQueryFilter, key, func() (*[]*A, error) {
+		result, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *loaded, nil
+}
+
+func getOrLoad[A, V any](ctx context.Context, c *entityCache[A], query string, key string, load func() (*V, error)) (*V, error) {
+	// Reads asking for the primary must see the latest write, which another replica's memory
+	// cache may not have dropped yet
+	if db.UsesPrimary(ctx) {
+		return load()
+	}
+
+	log := logger.FromContext(ctx)
+
+	data, found, err := c.store.Get(ctx, key)
+	if err != nil {
+		metrics.CacheRequests.WithLabelValues(string(c.name), query, "error").Inc()
+		log.Warn("cache unavailable, reading from the database", "entity", c.name, "error", err)
+		return load()
+	}
+	if found {
+		var value V
+		if err := json.Unmarshal(data, &value); err == nil {
+			metrics.CacheRequests.WithLabelValues(string(c.name), query, "hit").Inc()
+			return &value, nil
+		}
+		log.Warn("failed to decode cached value", "entity", c.name, "key", key, "error", err)
+	}
+	metrics.CacheRequests.WithLabelValues(string(c.name), query, "miss").Inc()
+
+	// The first caller loads the value for everyone waiting on the same key
+	result := c.group.DoChan(key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		generation, generationErr := c.counter(ctx, c.generationKey())
+
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if generationErr == nil {
+			c.fill(ctx, key, value, generation)
+		}
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case loaded := <-result:
+		if loaded.Err != nil {
+			return nil, loaded.Err
+		}
+		return loaded.Val.(*V), nil
+	}
+}
+
+// fill caches value at key unless a write changed the entity since generation was read. A write
+// landing between the check and the Set is caught by checking again after it: writes bump the
+// generation before deleting keys, so either the second check sees it or the write's delete comes
+// after the Set.
+func (c *entityCache[A]) fill(ctx context.Context, key string, value any, generation int64) {
+	log := logger.FromContext(ctx)
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	if current, err := c.counter(ctx, c.generationKey()); err != nil || current != generation {
+		return
+	}
+	if err := c.store.Set(ctx, key, data, c.settings.TTL); err != nil {
+		log.Warn("failed to cache value", "entity", c.name, "error", err)
+		return
+	}
+	if current, err := c.counter(ctx, c.generationKey()); err != nil || current != generation {
+		if err := c.store.Delete(ctx, key); err != nil {
+			log.Error("failed to drop stale cached value", "entity", c.name, "key", key, "error", err)
+		}
+	}
+}
+
+// invalidate drops the cached record of event and every cached filter result
+func (c *entityCache[A]) invalidate(ctx context.Context, event common.Event) {
+	tenant := "-"
+	if c.tenantScoped {
+		tenant = event.TenantID
+	}
+
+	log := logger.FromContext(ctx)
+	// Loads running now must not cache what they read, see fill
+	if _, err := c.store.Incr(ctx, c.generationKey()); err != nil {
+		log.Error("failed to bump cache generation", "entity", c.name, "error", err)
+	}
+	if err := c.store.Delete(ctx, c.idKey(tenant, event.EntityId), c.idKey("*", event.EntityId)); err != nil {
+		log.Error("failed to invalidate cached record", "entity", c.name, "id", event.EntityId, "error", err)
+	}
+	if c.settings.Filters {
+		if _, err := c.store.Incr(ctx, c.filterVersionKey()); err != nil {
+			log.Error("failed to invalidate cached filters", "entity", c.name, "error", err)
+		}
+	}
+}
+
+func (c *entityCache[A]) idKey(tenant string, id string) string {
+	return fmt.Sprintf("%s:%s:id:%s", c.name, tenant, id)
+}
+
+func (c *entityCache[A]) filterVersionKey() string {
+	return fmt.Sprintf("%s:filters:version", c.name)
+}
+
+func (c *entityCache[A]) generationKey() string {
+	return fmt.Sprintf("%s:generation", c.name)
+}
+
+func (c *entityCache[A]) filterVersion(ctx context.Context) (int64, error) {
+	return c.counter(ctx, c.filterVersionKey())
+}
+
+// counter reads a counter bumped with Incr, 0 until it is first bumped
+func (c *entityCache[A]) counter(ctx context.Context, key string) (int64, error) {
+	data, found, err := c.store.Get(ctx, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// tenantOf returns the tenant part of the keys read with ctx
+func (c *entityCache[A]) tenantOf(ctx context.Context) string {
+	if !c.tenantScoped {
+		return "-"
+	}
+	if common.IsAllTenants(ctx) {
+		return "*"
+	}
+	tenantId, _ := common.TenantFromContext(ctx)
+	return tenantId
+}
+
+var (
+	cacheInvalidatorsMutex sync.Mutex
+	cacheInvalidators      = map[common.EntityName]func(ctx context.Context, event common.Event){}
+)
+
+func registerCacheInvalidator(entityName common.EntityName, invalidate func(ctx context.Context, event common.Event)) {
+	cacheInvalidatorsMutex.Lock()
+	defer cacheInvalidatorsMutex.Unlock()
+	cacheInvalidators[entityName] = invalidate
+}
+
+// invalidateCaches drops the cached reads the events make stale
+func invalidateCaches(ctx context.Context, events []common.Event) {
+	cacheInvalidatorsMutex.Lock()
+	defer cacheInvalidatorsMutex.Unlock()
+
+	for _, event := range events {
+		if invalidate, ok := cacheInvalidators[event.EntityName]; ok {
+			invalidate(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/cache"
+)
+
+type cachedRecord struct {
+	ID   string
+	Name string
+}
+
+func newTestEntityCache(t *testing.T) *entityCache[cachedRecord] {
+	t.Helper()
+	entityCache, err := newEntityCache[cachedRecord]("CachedRecord", cache.Settings{
+		Backend:    cache.BACKEND_MEMORY,
+		TTL:        time.Minute,
+		MaxEntries: 100,
+		Filters:    true,
+	}, false)
+	if err != nil {
+		t.Fatalf("newEntityCache returned %v", err)
+	}
+	return entityCache
+}
+
+func TestFindByIdCachesTheRecord(t *testing.T) {
+	ctx := context.Background()
+	entityCache := newTestEntityCache(t)
+
+	loads := 0
+	load := func() (*cachedRecord, error) {
+		loads++
+		return &cachedRecord{ID: "1", Name: "first"}, nil
+	}
+	for range 2 {
+		if _, err := entityCache.findById(ctx, "1", load); err != nil {
+			t.Fatalf("findById returned %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loaded %d times, want the second read served from the cache", loads)
+	}
+
+	entityCache.invalidate(ctx, common.Event{EntityId: "1"})
+	if _, err := entityCache.findById(ctx, "1", load); err != nil {
+		t.Fatalf("findById returned %v", err)
+	}
+	if loads != 2 {
+		t.Errorf("loaded %d times, want the read after the write to load again", loads)
+	}
+}
+
+func TestFindByIdDoesNotCacheARecordWrittenWhileLoading(t *testing.T) {
+	ctx := context.Background()
+	entityCache := newTestEntityCache(t)
+
+	// The write commits and invalidates after the load read the old record
+	stale, err := entityCache.findById(ctx, "1", func() (*cachedRecord, error) {
+		entityCache.invalidate(ctx, common.Event{EntityId: "1"})
+		return &cachedRecord{ID: "1", Name: "before the write"}, nil
+	})
+	if err != nil {
+		t.Fatalf("findById returned %v", err)
+	}
+	if stale.Name != "before the write" {
+		t.Errorf("findById returned %q, want the loaded record", stale.Name)
+	}
+
+	fresh, err := entityCache.findById(ctx, "1", func() (*cachedRecord, error) {
+		return &cachedRecord{ID: "1", Name: "after the write"}, nil
+	})
+	if err != nil {
+		t.Fatalf("findById returned %v", err)
+	}
+	if fresh.Name != "after the write" {
+		t.Errorf("findById returned %q, the record loaded before the write was cached", fresh.Name)
+	}
+}
+
+func TestFindWithFilterDoesNotServeResultsOfAnOlderVersion(t *testing.T) {
+	ctx := context.Background()
+	entityCache := newTestEntityCache(t)
+	query := common.FilterQuery{Conditions: []common.Condition{{Key: "name", Value: "first", Operator: common.CONDITION_EQ}}}
+
+	if _, err := entityCache.findWithFilter(ctx, query, func() ([]*cachedRecord, error) {
+		entityCache.invalidate(ctx, common.Event{EntityId: "1"})
+		return []*cachedRecord{{ID: "1", Name: "first"}}, nil
+	}); err != nil {
+		t.Fatalf("findWithFilter returned %v", err)
+	}
+
+	result, err := entityCache.findWithFilter(ctx, query, func() ([]*cachedRecord, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("findWithFilter returned %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("findWithFilter returned %d records, the result loaded before the write was cached", len(result))
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/tracing"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
+	"gorm.io/gorm"
+)
+
+// recordEvents stores the events of event sourced entities within the write transaction.
+// Stored events are marked so the CRUD event worker only dispatches them.
+func recordEvents(tx *gorm.DB, events []common.Event) error {
+	actor := common.ActorFromContext(tx.Statement.Context)
+	requestId, _ := common.RequestIDFromContext(tx.Statement.Context)
+	traceContext := tracing.Inject(tx.Statement.Context)
+	for index := range events {
+		events[index].Actor = actor
+		events[index].RequestID = requestId
+		events[index].TraceContext = traceContext
+		event := events[index]
+		if !event.Config.EventSourced {
+			continue
+		}
+
+		if err := tx.Create(entity.NewEvent(aggregate.NewEvent(event))).Error; err != nil {
+			return fmt.Errorf("failed to store event: %w", err)
+		}
+		events[index].Config.EventStore = false
+	}
+	return nil
+}
+
+// publishEvents drops the cached reads the events make stale and pushes them to the CRUD event
+// channel. It must only be called once the transaction that produced the events has committed.
+// The write already succeeded, so an event the full channel has no room for is parked in the dead
+// letter table instead of holding up the request, and requeued on the next boot.
+func publishEvents(ctx context.Context, databases *db.Databases, events ...common.Event) {
+	invalidateCaches(ctx, events)
+
+	for _, event := range events {
+		if worker_channel.PushToCRUDChannel(event) {
+			continue
+		}
+
+		reason := fmt.Errorf("the %s channel was full", worker_channel.CHANNEL_CRUD)
+		deadLetter := aggregate.NewDeadLetterEvent(event, worker_channel.CHANNEL_CRUD, reason, 0)
+		// Parking the event must not be cut short by the request ending
+		if _, err := NewDeadLetterEventRepository(databases).Create(context.WithoutCancel(ctx), deadLetter); err != nil {
+			logger.FromContext(ctx).Error("failed to dead letter event, it is lost", "event_id", event.ID, "error", err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
 	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
@@ -10,11 +12,14 @@ import (
 )
 
 type EventRepository interface {
-	Create(event *aggregate.Event) (*aggregate.Event, error)
-	FindById(id string) (*aggregate.Event, error)
-	FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.Event, error)
-	Update(event *aggregate.Event) (*aggregate.Event, error)
-	Delete(id string) error
+	Create(ctx context.Context, event *aggregate.Event) (*aggregate.Event, error)
+	BulkCreate(ctx context.Context, events []*aggregate.Event) ([]*aggregate.Event, error)
+	FindById(ctx context.Context, id string) (*aggregate.Event, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.Event, error)
+	FindByEntity(ctx context.Context, entityName common.EntityName, entityId string) ([]*aggregate.Event, error)
+	FindLastByEntity(ctx context.Context, entityName common.EntityName, entityId string, at time.Time) (*aggregate.Event, error)
+	Update(ctx context.Context, event *aggregate.Event) (*aggregate.Event, error)
+	Delete(ctx context.Context, id string) error
 }
 
 // eventRepository implements the EventRepository interface.
@@ -30,23 +35,48 @@ func NewEventRepository(databases *db.Databases) EventRepository {
 }
 
 // Create inserts a new event.
-func (r *eventRepository) Create(event *aggregate.Event) (*aggregate.Event, error) {
+func (r *eventRepository) Create(ctx context.Context, event *aggregate.Event) (*aggregate.Event, error) {
 	entityEvent := r.toEntity(event)
-	createdEvent, err := r.BaseRepository.Create(entityEvent)
+	createdEvent, err := r.BaseRepository.WithContext(ctx).Create(entityEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toDomain(createdEvent), nil
+}
+
+// BulkCreate inserts a batch of events in a single statement.
+func (r *eventRepository) BulkCreate(ctx context.Context, events []*aggregate.Event) ([]*aggregate.Event, error) {
+	var entityList = make([]*entity.Event, 0, len(events))
+	for _, event := range events {
+		entityList = append(entityList, r.toEntity(event))
+	}
+
+	createdList, err := r.BaseRepository.WithContext(ctx).BulkCreate(entityList)
+	if err != nil {
+		return nil, err
+	}
 
-	return r.toDomain(createdEvent), err
+	var result = make([]*aggregate.Event, 0, len(createdList))
+	for _, event := range createdList {
+		result = append(result, r.toDomain(event))
+	}
+	return result, nil
 }
 
 // FindById retrieves a event by its ID.
-func (r *eventRepository) FindById(id string) (*aggregate.Event, error) {
-	entityEvent, err := r.BaseRepository.FindById(id)
-	return r.toDomain(entityEvent), err
+func (r *eventRepository) FindById(ctx context.Context, id string) (*aggregate.Event, error) {
+	entityEvent, err := r.BaseRepository.WithContext(ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(entityEvent), nil
 }
 
 // FindWithFilter retrieves a event by .
-func (r *eventRepository) FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.Event, error) {
+func (r *eventRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.Event, error) {
 
-	events, err := r.BaseRepository.FindWithFilter(filterQuery)
+	events, err := r.BaseRepository.WithContext(ctx).FindWithFilter(filterQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find events by name: %w", err)
 	}
@@ -60,17 +90,53 @@ func (r *eventRepository) FindWithFilter(filterQuery common.FilterQuery) ([]*agg
 	return result, nil
 }
 
+// FindByEntity retrieves the events of one record ordered from oldest to newest.
+func (r *eventRepository) FindByEntity(ctx context.Context, entityName common.EntityName, entityId string) ([]*aggregate.Event, error) {
+	var events []*entity.Event
+	err := r.db.WithContext(ctx).
+		Where("entity_name = ? AND entity_id = ?", string(entityName), entityId).
+		Order("created_at ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, dbError(err, fmt.Sprintf("find events of %s %s", entityName, entityId))
+	}
+
+	var result = make([]*aggregate.Event, 0, len(events))
+	for _, event := range events {
+		result = append(result, r.toDomain(event))
+	}
+
+	return result, nil
+}
+
+// FindLastByEntity retrieves the newest event of one record that happened at or before the given time.
+func (r *eventRepository) FindLastByEntity(ctx context.Context, entityName common.EntityName, entityId string, at time.Time) (*aggregate.Event, error) {
+	var event entity.Event
+	err := r.db.WithContext(ctx).
+		Where("entity_name = ? AND entity_id = ? AND created_at <= ?", string(entityName), entityId, at).
+		Order("created_at DESC").
+		First(&event).Error
+	if err != nil {
+		return nil, dbError(err, fmt.Sprintf("find last event of %s %s", entityName, entityId))
+	}
+
+	return r.toDomain(&event), nil
+}
+
 // Update modifies an existing event.
-func (r *eventRepository) Update(event *aggregate.Event) (*aggregate.Event, error) {
+func (r *eventRepository) Update(ctx context.Context, event *aggregate.Event) (*aggregate.Event, error) {
 	entityEvent := r.toEntity(event)
-	updatedEvent, err := r.BaseRepository.Update(entityEvent)
+	updatedEvent, err := r.BaseRepository.WithContext(ctx).Update(entityEvent)
+	if err != nil {
+		return nil, err
+	}
 
-	return r.toDomain(updatedEvent), err
+	return r.toDomain(updatedEvent), nil
 }
 
 // Delete removes a event by its ID.
-func (r *eventRepository) Delete(id string) error {
-	err := r.BaseRepository.Delete(id)
+func (r *eventRepository) Delete(ctx context.Context, id string) error {
+	err := r.BaseRepository.WithContext(ctx).Delete(id)
 	return err
 }
 
@@ -80,7 +146,10 @@ func (r *eventRepository) toEntity(event *aggregate.Event) *entity.Event {
 		ID:         event.ID,
 		EntityId:   event.EntityId,
 		EntityName: event.EntityName,
+		TenantID:   event.TenantID,
+		Actor:      event.Actor,
 		Type:       event.Type,
+		Data:       event.Data,
 		CreatedAt:  event.CreatedAt,
 		UpdatedAt:  event.UpdatedAt,
 	}
@@ -92,7 +161,10 @@ func (r *eventRepository) toDomain(event *entity.Event) *aggregate.Event {
 		ID:         event.ID,
 		EntityId:   event.EntityId,
 		EntityName: event.EntityName,
+		TenantID:   event.TenantID,
+		Actor:      event.Actor,
 		Type:       event.Type,
+		Data:       event.Data,
 		CreatedAt:  event.CreatedAt,
 		UpdatedAt:  event.UpdatedAt,
 	}
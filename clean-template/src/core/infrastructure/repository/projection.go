@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const replayPageSize = 1000
+
+// Projection rebuilds an entity table from the events table
+type Projection interface {
+	// Replay applies all events of the entity to table and returns the number of events applied.
+	// When table is the entity's own table it is emptied first.
+	Replay(ctx context.Context, table string) (int, error)
+	TableName() (string, error)
+	Config() common.EntityConfig
+}
+
+var (
+	projectionsMutex sync.Mutex
+	projections      = map[common.EntityName]Projection{}
+)
+
+func registerProjection(entityName common.EntityName, projection Projection) {
+	projectionsMutex.Lock()
+	defer projectionsMutex.Unlock()
+	projections[entityName] = projection
+}
+
+// GetProjection returns the projection of a generated entity, e.g. "User"
+func GetProjection(entityName common.EntityName) (Projection, bool) {
+	GetRepositories()
+
+	projectionsMutex.Lock()
+	defer projectionsMutex.Unlock()
+	projection, ok := projections[entityName]
+	return projection, ok
+}
+
+// replayEvents upserts the snapshot of every event of entityName into table, in event order.
+// A is the aggregate stored as the event snapshot, T the entity mapped to the table.
+// It reads the events of all tenants; each snapshot is written with the tenant of its event.
+func replayEvents[T any, A any](ctx context.Context, db *gorm.DB, entityName common.EntityName, table string, toEntity func(*A) *T) (int, error) {
+	currentTable, err := tableNameOf[T](db)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = common.WithAllTenants(ctx)
+	db = db.WithContext(ctx)
+
+	applied := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if table == currentTable {
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(new(T)).Error; err != nil {
+				return fmt.Errorf("failed to empty %s: %w", table, err)
+			}
+		} else {
+			if tx.Migrator().HasTable(table) {
+				return fmt.Errorf("table %s already exists, replay needs a fresh table", table)
+			}
+			// Copies columns, defaults and indexes of the current table
+			if err := tx.Exec("CREATE TABLE ? (LIKE ? INCLUDING ALL)", clause.Table{Name: table}, clause.Table{Name: currentTable}).Error; err != nil {
+				return fmt.Errorf("failed to create %s: %w", table, err)
+			}
+		}
+
+		for offset := 0; ; offset += replayPageSize {
+			var events []*entity.Event
+			err := tx.Where("entity_name = ?", string(entityName)).
+				Order("created_at ASC").Order("id ASC").
+				Limit(replayPageSize).Offset(offset).
+				Find(&events).Error
+			if err != nil {
+				return fmt.Errorf("failed to read events: %w", err)
+			}
+
+			for _, event := range events {
+				// Replayed rows keep the tenant and the audit fields of their events
+				eventCtx := common.WithActor(ctx, event.Actor)
+				if event.TenantID != "" {
+					eventCtx = common.WithTenant(eventCtx, event.TenantID)
+				}
+				eventTx := tx.WithContext(eventCtx)
+				if err := applyEvent(eventTx, table, event, toEntity); err != nil {
+					return fmt.Errorf("failed to apply event %s: %w", event.ID, err)
+				}
+				applied++
+			}
+
+			if len(events) < replayPageSize {
+				return nil
+			}
+			logger.FromContext(ctx).Info("replay in progress", "entity_name", entityName, "applied", applied)
+		}
+	})
+
+	return applied, err
+}
+
+func applyEvent[T any, A any](tx *gorm.DB, table string, event *entity.Event, toEntity func(*A) *T) error {
+	if event.Data == "" {
+		return fmt.Errorf("event has no snapshot")
+	}
+
+	var snapshot A
+	if err := json.Unmarshal([]byte(event.Data), &snapshot); err != nil {
+		return err
+	}
+
+	record := toEntity(&snapshot)
+	onConflict := clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, UpdateAll: true}
+	if _, ok := any(record).(common.AuditedModel); ok {
+		// Later events must not overwrite who created the record
+		columns, err := upsertColumns(tx, record, db.CreatedByColumn)
+		if err != nil {
+			return err
+		}
+		onConflict = clause.OnConflict{Columns: onConflict.Columns, DoUpdates: clause.AssignmentColumns(columns)}
+	}
+
+	if err := tx.Table(table).Clauses(onConflict).Create(record).Error; err != nil {
+		return err
+	}
+
+	if common.EventType(event.Type) == common.ENTITY_DELETED {
+		return tx.Table(table).Where("id = ?", event.EntityId).Delete(new(T)).Error
+	}
+	return nil
+}
+
+// upsertColumns lists the columns UpdateAll would update on conflict, except the omitted ones
+func upsertColumns(tx *gorm.DB, model any, omit ...string) ([]string, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.PrimaryKey || field.AutoCreateTime != 0 || slices.Contains(omit, field.DBName) {
+			continue
+		}
+		if field.HasDefaultValue && field.DefaultValueInterface == nil {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+	return columns, nil
+}
+
+func tableNameOf[T any](db *gorm.DB) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
@@ -7,8 +7,11 @@ import (
 )
 
 type Repositories struct {
-	EventRepository          EventRepository
-	TemplateEntityRepository TemplateEntityRepository
+	EventRepository               EventRepository
+	DeadLetterEventRepository     DeadLetterEventRepository
+	WebhookSubscriptionRepository WebhookSubscriptionRepository
+	WebhookDeliveryRepository     WebhookDeliveryRepository
+	TemplateEntityRepository      TemplateEntityRepository
 }
 
 var (
@@ -20,8 +23,11 @@ func GetRepositories() *Repositories {
 	var databases = db.ConnectAll()
 	repositoriesOnce.Do(func() {
 		allRepositories = &Repositories{
-			EventRepository:          NewEventRepository(databases),
-			TemplateEntityRepository: NewTemplateEntityRepository(databases),
+			EventRepository:               NewEventRepository(databases),
+			DeadLetterEventRepository:     NewDeadLetterEventRepository(databases),
+			WebhookSubscriptionRepository: NewWebhookSubscriptionRepository(databases),
+			WebhookDeliveryRepository:     NewWebhookDeliveryRepository(databases),
+			TemplateEntityRepository:      NewTemplateEntityRepository(databases),
 		}
 	})
 	return allRepositories
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/cache"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+)
+
+// templateEntityCacheSettings is the "cache" block of templateEntity in gStructify.config.json
+var templateEntityCacheSettings = TEMPLATE_CACHE_SETTINGS
+
+// cachedTemplateEntityRepository serves the reads of TemplateEntityRepository from a cache.
+// Writes go through the wrapped repository, whose published events invalidate the cache. Misses
+// read from the primary, a lagging replica would put back a value a write just invalidated.
+type cachedTemplateEntityRepository struct {
+	TemplateEntityRepository
+	cache *entityCache[aggregate.TemplateEntity]
+}
+
+// newCachedTemplateEntityRepository wraps repository with the cache of templateEntityCacheSettings
+func newCachedTemplateEntityRepository(repository TemplateEntityRepository) (TemplateEntityRepository, error) {
+	_, tenantScoped := any(&entity.TemplateEntity{}).(common.TenantScopedModel)
+	entityCache, err := newEntityCache[aggregate.TemplateEntity](entity.TemplateEntityEntityName, templateEntityCacheSettings, tenantScoped)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedTemplateEntityRepository{TemplateEntityRepository: repository, cache: entityCache}, nil
+}
+
+// FindById retrieves a templateEntity by its ID from the cache, or the database on a miss.
+func (r *cachedTemplateEntityRepository) FindById(ctx context.Context, id string) (*aggregate.TemplateEntity, error) {
+	return r.cache.findById(ctx, id, func() (*aggregate.TemplateEntity, error) {
+		return r.TemplateEntityRepository.FindById(db.WithPrimary(ctx), id)
+	})
+}
+
+// FindWithFilter retrieves templateEntitys from the cache when filters are cached, or the database.
+func (r *cachedTemplateEntityRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error) {
+	return r.cache.findWithFilter(ctx, filterQuery, func() ([]*aggregate.TemplateEntity, error) {
+		return r.TemplateEntityRepository.FindWithFilter(db.WithPrimary(ctx), filterQuery)
+	})
+}
@@ -1,83 +1,148 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
 	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
-	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/worker_channel"
 )
 
 type TemplateEntityRepository interface {
-	Create(templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error)
-	BulkCreate(templateEntity []*aggregate.TemplateEntity) ([]*aggregate.TemplateEntity, error)
-	FindById(id string) (*aggregate.TemplateEntity, error)
-	FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error)
-	Update(templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error)
-	Delete(id string) error
+	Create(ctx context.Context, templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error)
+	BulkCreate(ctx context.Context, templateEntity []*aggregate.TemplateEntity) ([]*aggregate.TemplateEntity, error)
+	FindById(ctx context.Context, id string) (*aggregate.TemplateEntity, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error)
+	Update(ctx context.Context, templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*aggregate.TemplateEntity, error)
+	FindHistory(ctx context.Context, id string) ([]*aggregate.Event, error)
+	AsOf(ctx context.Context, id string, at time.Time) (*aggregate.TemplateEntity, error)
 }
 
 // templateEntityRepository implements the TemplateEntityRepository interface.
 type templateEntityRepository struct {
 	*BaseRepository[entity.TemplateEntity] // Embeds BaseRepository for CRUD operations
+	eventRepository                        EventRepository
+	databases                              *db.Databases
 }
 
 // NewTemplateEntityRepository initializes a new templateEntityRepository instance.
 func NewTemplateEntityRepository(databases *db.Databases) TemplateEntityRepository {
-	return &templateEntityRepository{
-		BaseRepository: NewBaseRepository[entity.TemplateEntity](databases.SqlDB.DB), // Initialize BaseRepository with the entity.TemplateEntity type
+	repository := &templateEntityRepository{
+		BaseRepository:  NewBaseRepository[entity.TemplateEntity](databases.SqlDB.DB), // Initialize BaseRepository with the entity.TemplateEntity type
+		eventRepository: NewEventRepository(databases),
+		databases:       databases,
 	}
+	registerProjection(entity.TemplateEntityEntityName, repository)
+
+	if !templateEntityCacheSettings.Enabled() {
+		return repository
+	}
+	cachedRepository, err := newCachedTemplateEntityRepository(repository)
+	if err != nil {
+		slog.Error("templateEntity cache disabled", "error", err)
+		return repository
+	}
+	return cachedRepository
 }
 
 // Create inserts a new templateEntity.
-func (r *templateEntityRepository) Create(templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error) {
-	entityTemplateEntity := entity.NewTemplateEntity(templateEntity)
-	createdTemplateEntity, err := r.BaseRepository.Create(entityTemplateEntity)
+func (r *templateEntityRepository) Create(ctx context.Context, templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error) {
+	var createdTemplateEntity *entity.TemplateEntity
+	var events []common.Event
+
+	err := r.BaseRepository.WithContext(ctx).Transaction(func(txRepo *BaseRepository[entity.TemplateEntity]) error {
+		if err := aggregate.RunBeforeCreate(templateEntity); err != nil {
+			return err
+		}
+
+		created, err := txRepo.Create(entity.NewTemplateEntity(templateEntity))
+		if err != nil {
+			return err
+		}
+		createdTemplateEntity = created
+
+		if err := aggregate.RunAfterCreate(created.ToDomain()); err != nil {
+			return err
+		}
+
+		events = []common.Event{created.GetCreatedEvent()}
+		return recordEvents(txRepo.db, events)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	eventChannel := worker_channel.GetCRUDEventChannel()
-	eventChannel <- entityTemplateEntity.GetCreatedEvent()
+	publishEvents(ctx, r.databases, events...)
 
 	return createdTemplateEntity.ToDomain(), nil
 }
 
 // Bulk inserts a new templateEntity.
-func (r *templateEntityRepository) BulkCreate(aggregateList []*aggregate.TemplateEntity) ([]*aggregate.TemplateEntity, error) {
+func (r *templateEntityRepository) BulkCreate(ctx context.Context, aggregateList []*aggregate.TemplateEntity) ([]*aggregate.TemplateEntity, error) {
+	var createdList []*entity.TemplateEntity
+	var events []common.Event
 
-	var entityList = make([]*entity.TemplateEntity, 0, len(aggregateList))
+	err := r.BaseRepository.WithContext(ctx).Transaction(func(txRepo *BaseRepository[entity.TemplateEntity]) error {
+		var entityList = make([]*entity.TemplateEntity, 0, len(aggregateList))
 
-	for _, each := range aggregateList {
-		entityList = append(entityList, entity.NewTemplateEntity(each))
-	}
+		for _, each := range aggregateList {
+			if err := aggregate.RunBeforeCreate(each); err != nil {
+				return err
+			}
+			entityList = append(entityList, entity.NewTemplateEntity(each))
+		}
 
-	createdList, err := r.BaseRepository.BulkCreate(entityList)
+		created, err := txRepo.BulkCreate(entityList)
+		if err != nil {
+			return err
+		}
+		createdList = created
+
+		events = make([]common.Event, 0, len(created))
+		for _, each := range created {
+			if err := aggregate.RunAfterCreate(each.ToDomain()); err != nil {
+				return err
+			}
+			events = append(events, each.GetCreatedEvent())
+		}
+		return recordEvents(txRepo.db, events)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	var result = make([]*aggregate.TemplateEntity, 0, len(createdList))
 	for _, each := range createdList {
-		eventChannel := worker_channel.GetCRUDEventChannel()
-		eventChannel <- each.GetCreatedEvent()
-
+		result = append(result, each.ToDomain())
 	}
-	return aggregateList, nil
+
+	publishEvents(ctx, r.databases, events...)
+
+	return result, nil
 }
 
-// FindById retrieves a templateEntity by its ID.
-func (r *templateEntityRepository) FindById(id string) (*aggregate.TemplateEntity, error) {
-	entityTemplateEntity, err := r.BaseRepository.FindById(id)
-	return entityTemplateEntity.ToDomain(), err
+// FindById retrieves a templateEntity by its ID, from a replica when there are replicas.
+func (r *templateEntityRepository) FindById(ctx context.Context, id string) (*aggregate.TemplateEntity, error) {
+	entityTemplateEntity, err := readerOf[entity.TemplateEntity](ctx, r.databases).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	return entityTemplateEntity.ToDomain(), nil
 }
 
-// FindWithFilter retrieves a templateEntity by .
-func (r *templateEntityRepository) FindWithFilter(filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error) {
+// FindWithFilter retrieves templateEntitys matching filterQuery, from a replica when there are replicas.
+func (r *templateEntityRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.TemplateEntity, error) {
 
-	templateEntitys, err := r.BaseRepository.FindWithFilter(filterQuery)
+	templateEntitys, err := readerOf[entity.TemplateEntity](ctx, r.databases).FindWithFilter(filterQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find templateEntitys by name: %w", err)
 	}
@@ -92,27 +157,161 @@ func (r *templateEntityRepository) FindWithFilter(filterQuery common.FilterQuery
 }
 
 // Update modifies an existing templateEntity.
-func (r *templateEntityRepository) Update(templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error) {
-	entityTemplateEntity := entity.NewTemplateEntity(templateEntity)
-	updatedTemplateEntity, err := r.BaseRepository.Update(entityTemplateEntity)
+func (r *templateEntityRepository) Update(ctx context.Context, templateEntity *aggregate.TemplateEntity) (*aggregate.TemplateEntity, error) {
+	var updatedTemplateEntity *entity.TemplateEntity
+	var events []common.Event
+
+	err := r.BaseRepository.WithContext(ctx).Transaction(func(txRepo *BaseRepository[entity.TemplateEntity]) error {
+		existing, err := txRepo.FindById(templateEntity.ID)
+		if err != nil {
+			return err
+		}
+		previous := existing.ToDomain()
+
+		if err := aggregate.RunBeforeUpdate(templateEntity, previous); err != nil {
+			return err
+		}
+
+		// Keep the original creation time, the update DTO does not carry it
+		templateEntity.CreatedAt = previous.CreatedAt
+
+		updated, err := txRepo.Update(entity.NewTemplateEntity(templateEntity))
+		if err != nil {
+			return err
+		}
+		updatedTemplateEntity = updated
+
+		if err := aggregate.RunAfterUpdate(updated.ToDomain(), previous); err != nil {
+			return err
+		}
+
+		events = []common.Event{updated.GetUpdatedEvent()}
+		return recordEvents(txRepo.db, events)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	eventChannel := worker_channel.GetCRUDEventChannel()
-	eventChannel <- updatedTemplateEntity.GetUpdatedEvent()
+	publishEvents(ctx, r.databases, events...)
 
-	return updatedTemplateEntity.ToDomain(), err
+	return updatedTemplateEntity.ToDomain(), nil
 }
 
 // Delete removes a templateEntity by its ID.
-func (r *templateEntityRepository) Delete(id string) error {
-	err := r.BaseRepository.Delete(id)
+func (r *templateEntityRepository) Delete(ctx context.Context, id string) error {
+	var events []common.Event
+
+	err := r.BaseRepository.WithContext(ctx).Transaction(func(txRepo *BaseRepository[entity.TemplateEntity]) error {
+		existing, err := txRepo.FindById(id)
+		if err != nil {
+			return err
+		}
+
+		if err := aggregate.RunBeforeDelete(existing.ToDomain()); err != nil {
+			return err
+		}
+
+		if err := txRepo.Delete(id); err != nil {
+			return err
+		}
+
+		if err := aggregate.RunAfterDelete(existing.ToDomain()); err != nil {
+			return err
+		}
+
+		events = []common.Event{existing.GetDeletedEvent()}
+		return recordEvents(txRepo.db, events)
+	})
+
 	if err != nil {
-		entity := entity.TemplateEntity{ID: id}
-		eventChannel := worker_channel.GetCRUDEventChannel()
-		eventChannel <- entity.GetUpdatedEvent()
+		return err
 	}
-	return err
+
+	publishEvents(ctx, r.databases, events...)
+
+	return nil
+}
+
+// Restore brings back a deleted templateEntity by its ID.
+func (r *templateEntityRepository) Restore(ctx context.Context, id string) (*aggregate.TemplateEntity, error) {
+	var restoredTemplateEntity *entity.TemplateEntity
+	var events []common.Event
+
+	err := r.BaseRepository.WithContext(ctx).Transaction(func(txRepo *BaseRepository[entity.TemplateEntity]) error {
+		deleted, err := txRepo.FindDeletedById(id)
+		if err != nil {
+			return err
+		}
+
+		if err := aggregate.RunBeforeRestore(deleted.ToDomain()); err != nil {
+			return err
+		}
+
+		if err := txRepo.Restore(id); err != nil {
+			return err
+		}
+
+		restored, err := txRepo.FindById(id)
+		if err != nil {
+			return err
+		}
+		restoredTemplateEntity = restored
+
+		if err := aggregate.RunAfterRestore(restored.ToDomain()); err != nil {
+			return err
+		}
+
+		events = []common.Event{restored.GetRestoredEvent()}
+		return recordEvents(txRepo.db, events)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvents(ctx, r.databases, events...)
+
+	return restoredTemplateEntity.ToDomain(), nil
+}
+
+// FindHistory retrieves the stored events of a templateEntity, oldest first.
+func (r *templateEntityRepository) FindHistory(ctx context.Context, id string) ([]*aggregate.Event, error) {
+	return r.eventRepository.FindByEntity(ctx, entity.TemplateEntityEntityName, id)
+}
+
+// AsOf reconstructs a templateEntity as it was at the given time from its latest event snapshot.
+func (r *templateEntityRepository) AsOf(ctx context.Context, id string, at time.Time) (*aggregate.TemplateEntity, error) {
+	event, err := r.eventRepository.FindLastByEntity(ctx, entity.TemplateEntityEntityName, id, at)
+	if err != nil {
+		return nil, err
+	}
+
+	if common.EventType(event.Type) == common.ENTITY_DELETED {
+		return nil, apperror.NotFound(common.RecordNotFoundError)
+	}
+
+	if event.Data == "" {
+		return nil, fmt.Errorf("event %s has no snapshot", event.ID)
+	}
+
+	var templateEntity aggregate.TemplateEntity
+	if err := json.Unmarshal([]byte(event.Data), &templateEntity); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of event %s: %w", event.ID, err)
+	}
+
+	return &templateEntity, nil
+}
+
+// Replay rebuilds table from the stored templateEntity events.
+func (r *templateEntityRepository) Replay(ctx context.Context, table string) (int, error) {
+	return replayEvents(ctx, r.db, entity.TemplateEntityEntityName, table, entity.NewTemplateEntity)
+}
+
+func (r *templateEntityRepository) TableName() (string, error) {
+	return tableNameOf[entity.TemplateEntity](r.db)
+}
+
+func (r *templateEntityRepository) Config() common.EntityConfig {
+	return entity.TemplateEntityConfig
 }
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+)
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, webhookDelivery *aggregate.WebhookDelivery) (*aggregate.WebhookDelivery, error)
+	FindById(ctx context.Context, id string) (*aggregate.WebhookDelivery, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookDelivery, error)
+	Update(ctx context.Context, webhookDelivery *aggregate.WebhookDelivery) (*aggregate.WebhookDelivery, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*aggregate.WebhookDelivery, error)
+}
+
+// webhookDeliveryRepository implements the WebhookDeliveryRepository interface.
+type webhookDeliveryRepository struct {
+	*BaseRepository[entity.WebhookDelivery] // Embeds BaseRepository for CRUD operations
+}
+
+// NewWebhookDeliveryRepository initializes a new webhookDeliveryRepository instance.
+func NewWebhookDeliveryRepository(databases *db.Databases) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		BaseRepository: NewBaseRepository[entity.WebhookDelivery](databases.SqlDB.DB),
+	}
+}
+
+// Create inserts a new webhook delivery log.
+func (r *webhookDeliveryRepository) Create(ctx context.Context, webhookDelivery *aggregate.WebhookDelivery) (*aggregate.WebhookDelivery, error) {
+	created, err := r.BaseRepository.WithContext(ctx).Create(entity.NewWebhookDelivery(webhookDelivery))
+	if err != nil {
+		return nil, err
+	}
+	return created.ToDomain(), nil
+}
+
+// FindById retrieves a webhook delivery log by its ID.
+func (r *webhookDeliveryRepository) FindById(ctx context.Context, id string) (*aggregate.WebhookDelivery, error) {
+	webhookDelivery, err := r.BaseRepository.WithContext(ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	return webhookDelivery.ToDomain(), nil
+}
+
+// FindWithFilter retrieves webhook delivery logs matching the filter.
+func (r *webhookDeliveryRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookDelivery, error) {
+	webhookDeliveries, err := r.BaseRepository.WithContext(ctx).FindWithFilter(filterQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+
+	var result []*aggregate.WebhookDelivery
+	for _, webhookDelivery := range webhookDeliveries {
+		result = append(result, webhookDelivery.ToDomain())
+	}
+
+	return result, nil
+}
+
+// Update modifies an existing webhook delivery log.
+func (r *webhookDeliveryRepository) Update(ctx context.Context, webhookDelivery *aggregate.WebhookDelivery) (*aggregate.WebhookDelivery, error) {
+	updated, err := r.BaseRepository.WithContext(ctx).Update(entity.NewWebhookDelivery(webhookDelivery))
+	if err != nil {
+		return nil, err
+	}
+	return updated.ToDomain(), nil
+}
+
+// FindDue retrieves the deliveries whose scheduled retry is due, the longest waiting first.
+func (r *webhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*aggregate.WebhookDelivery, error) {
+	var webhookDeliveries []*entity.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", string(aggregate.WEBHOOK_DELIVERY_RETRYING), now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&webhookDeliveries).Error
+	if err != nil {
+		return nil, dbError(err, "find due webhook deliveries")
+	}
+
+	var result = make([]*aggregate.WebhookDelivery, 0, len(webhookDeliveries))
+	for _, webhookDelivery := range webhookDeliveries {
+		result = append(result, webhookDelivery.ToDomain())
+	}
+
+	return result, nil
+}
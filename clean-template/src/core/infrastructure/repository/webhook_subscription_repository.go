@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, webhookSubscription *aggregate.WebhookSubscription) (*aggregate.WebhookSubscription, error)
+	FindById(ctx context.Context, id string) (*aggregate.WebhookSubscription, error)
+	FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookSubscription, error)
+	FindActive(ctx context.Context) ([]*aggregate.WebhookSubscription, error)
+	Update(ctx context.Context, webhookSubscription *aggregate.WebhookSubscription) (*aggregate.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// webhookSubscriptionRepository implements the WebhookSubscriptionRepository interface.
+type webhookSubscriptionRepository struct {
+	*BaseRepository[entity.WebhookSubscription] // Embeds BaseRepository for CRUD operations
+}
+
+// NewWebhookSubscriptionRepository initializes a new webhookSubscriptionRepository instance.
+func NewWebhookSubscriptionRepository(databases *db.Databases) WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepository{
+		BaseRepository: NewBaseRepository[entity.WebhookSubscription](databases.SqlDB.DB),
+	}
+}
+
+// Create inserts a new webhook subscription.
+func (r *webhookSubscriptionRepository) Create(ctx context.Context, webhookSubscription *aggregate.WebhookSubscription) (*aggregate.WebhookSubscription, error) {
+	created, err := r.BaseRepository.WithContext(ctx).Create(entity.NewWebhookSubscription(webhookSubscription))
+	if err != nil {
+		return nil, err
+	}
+	return created.ToDomain(), nil
+}
+
+// FindById retrieves a webhook subscription by its ID.
+func (r *webhookSubscriptionRepository) FindById(ctx context.Context, id string) (*aggregate.WebhookSubscription, error) {
+	webhookSubscription, err := r.BaseRepository.WithContext(ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	return webhookSubscription.ToDomain(), nil
+}
+
+// FindWithFilter retrieves webhook subscriptions matching the filter.
+func (r *webhookSubscriptionRepository) FindWithFilter(ctx context.Context, filterQuery common.FilterQuery) ([]*aggregate.WebhookSubscription, error) {
+	webhookSubscriptions, err := r.BaseRepository.WithContext(ctx).FindWithFilter(filterQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscriptions: %w", err)
+	}
+
+	var result []*aggregate.WebhookSubscription
+	for _, webhookSubscription := range webhookSubscriptions {
+		result = append(result, webhookSubscription.ToDomain())
+	}
+
+	return result, nil
+}
+
+// FindActive retrieves all active webhook subscriptions.
+func (r *webhookSubscriptionRepository) FindActive(ctx context.Context) ([]*aggregate.WebhookSubscription, error) {
+	var webhookSubscriptions []*entity.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&webhookSubscriptions).Error; err != nil {
+		return nil, dbError(err, "find active webhook subscriptions")
+	}
+
+	var result []*aggregate.WebhookSubscription
+	for _, webhookSubscription := range webhookSubscriptions {
+		result = append(result, webhookSubscription.ToDomain())
+	}
+
+	return result, nil
+}
+
+// Update modifies an existing webhook subscription.
+func (r *webhookSubscriptionRepository) Update(ctx context.Context, webhookSubscription *aggregate.WebhookSubscription) (*aggregate.WebhookSubscription, error) {
+	updated, err := r.BaseRepository.WithContext(ctx).Update(entity.NewWebhookSubscription(webhookSubscription))
+	if err != nil {
+		return nil, err
+	}
+	return updated.ToDomain(), nil
+}
+
+// Delete removes a webhook subscription by its ID.
+func (r *webhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return r.BaseRepository.WithContext(ctx).Delete(id)
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	EXPORTER_OTLP    = "otlp"
+	EXPORTER_CONSOLE = "console"
+	EXPORTER_NONE    = "none"
+)
+
+const tracerName = "github.com/nanda03dev/go-ms-template"
+
+// Setup installs the tracer provider selected by OTEL_TRACES_EXPORTER: "otlp" exports over OTLP/HTTP
+// to OTEL_EXPORTER_OTLP_ENDPOINT, "console" prints spans to stdout and "none" records nothing.
+// It defaults to "otlp" when an endpoint is set and to "none" otherwise. The returned function
+// flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	// Trace context is read from and written to W3C traceparent headers in every mode
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName() {
+	case EXPORTER_OTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case EXPORTER_CONSOLE:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case EXPORTER_NONE:
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q, expected %s, %s or %s", os.Getenv("OTEL_TRACES_EXPORTER"), EXPORTER_OTLP, EXPORTER_CONSOLE, EXPORTER_NONE)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// The resource takes the service name from OTEL_SERVICE_NAME
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.Default()),
+	)
+	otel.SetTracerProvider(provider)
+	slog.Info("tracing enabled", "exporter", exporterName())
+
+	return provider.Shutdown, nil
+}
+
+func exporterName() string {
+	if name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); name != "" {
+		return name
+	}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		return EXPORTER_OTLP
+	}
+	return EXPORTER_NONE
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span as a child of the span of ctx
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, options...)
+}
+
+// End records err on span, if any, and ends it. Use it with a named error result:
+//
+//	ctx, span := tracing.Start(ctx, "UserService.Create")
+//	defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns the trace context of ctx as a map, to carry it over a channel or a message
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// Extract returns ctx with the trace context of a carrier written by Inject, so spans started
+// from it continue that trace
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// LinkFrom links a span to the trace carried by carrier, for work done for several traces at once
+func LinkFrom(carrier map[string]string) (trace.Link, bool) {
+	spanContext := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	return trace.Link{SpanContext: spanContext}, spanContext.IsValid()
+}
+
+// TraceID returns the id of the trace of ctx, or "" when ctx is not traced
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
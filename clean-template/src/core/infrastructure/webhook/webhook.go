@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader  = "X-Webhook-Signature"
+	TimestampHeader  = "X-Webhook-Timestamp"
+	DeliveryIdHeader = "X-Webhook-Delivery"
+	EventTypeHeader  = "X-Webhook-Event"
+)
+
+// Request is one signed POST to a subscriber
+type Request struct {
+	TargetUrl  string
+	Secret     string
+	DeliveryId string
+	EventType  string
+	Payload    []byte
+}
+
+// Sender posts signed webhook payloads. Client can be replaced, e.g. to target an httptest server.
+type Sender struct {
+	Client *http.Client
+}
+
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{Client: client}
+}
+
+// Send posts the payload and returns the response status. Non 2xx responses are errors.
+// The request is abandoned when ctx is done.
+func (s *Sender) Send(ctx context.Context, request Request) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, request.TargetUrl, bytes.NewReader(request.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set(TimestampHeader, timestamp)
+	httpRequest.Header.Set(SignatureHeader, "sha256="+Sign(request.Secret, timestamp, request.Payload))
+	httpRequest.Header.Set(DeliveryIdHeader, request.DeliveryId)
+	httpRequest.Header.Set(EventTypeHeader, request.EventType)
+
+	response, err := s.Client.Do(httpRequest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook receiver responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" with the subscription secret.
+// Receivers recompute it from the X-Webhook-Timestamp header and the raw body to verify a delivery.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a "sha256=<hex>" signature header in constant time
+func Verify(secret, timestamp string, payload []byte, signature string) bool {
+	expected := "sha256=" + Sign(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
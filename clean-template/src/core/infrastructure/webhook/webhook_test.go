@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSendSignsTheRequest(t *testing.T) {
+	payload := []byte(`{"id":"event-1"}`)
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	status, err := NewSender(server.Client()).Send(context.Background(), Request{
+		TargetUrl:  server.URL,
+		Secret:     "secret",
+		DeliveryId: "delivery-1",
+		EventType:  "CREATED",
+		Payload:    payload,
+	})
+	if err != nil {
+		t.Fatalf("Send returned %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", received.Method)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		DeliveryIdHeader: "delivery-1",
+		EventTypeHeader:  "CREATED",
+	}
+	for name, want := range headers {
+		if got := received.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	timestamp := received.Header.Get(TimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("%s = %q is not a Unix time", TimestampHeader, timestamp)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age < 0 || age > time.Minute {
+		t.Errorf("%s = %s is not the time of the attempt", TimestampHeader, timestamp)
+	}
+
+	signature := received.Header.Get(SignatureHeader)
+	if want := "sha256=" + Sign("secret", timestamp, payload); signature != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, signature, want)
+	}
+	if !Verify("secret", timestamp, body, signature) {
+		t.Error("Verify rejected the signature of the request")
+	}
+	if Verify("other secret", timestamp, body, signature) {
+		t.Error("Verify accepted the signature with another secret")
+	}
+	if Verify("secret", timestamp, []byte(`{"id":"event-2"}`), signature) {
+		t.Error("Verify accepted the signature for another payload")
+	}
+}
+
+func TestSendFailsOnNon2xx(t *testing.T) {
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		got, err := NewSender(server.Client()).Send(context.Background(), Request{TargetUrl: server.URL, Payload: []byte(`{}`)})
+		server.Close()
+
+		if err == nil {
+			t.Errorf("status %d: Send returned no error", status)
+		}
+		if got != status {
+			t.Errorf("status %d: Send returned status %d", status, got)
+		}
+	}
+}
+
+func TestSendStopsWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := NewSender(server.Client()).Send(ctx, Request{TargetUrl: server.URL, Payload: []byte(`{}`)}); err == nil {
+		t.Fatal("Send returned no error once the context was done")
+	}
+}
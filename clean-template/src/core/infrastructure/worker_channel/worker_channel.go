@@ -1,22 +1,61 @@
 package worker_channel
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
 )
 
 // Declare a global channel
-var crudEventChannel = make(chan common.Event, 10000)
+var crudEventChannel chan common.Event
+
+// Events stored by the CRUD event worker are forwarded here for webhook delivery
+var webhookEventChannel chan common.Event
+
+// Events stored by the CRUD event worker are forwarded here for the in-process event bus
+var domainEventChannel chan common.Event
+
+// Fraction of a channel's capacity above which readiness fails
+const channelSaturationThreshold = 0.9
+
+// Names of the channels, in metrics and in the dead letters of the events taken from them
+const (
+	CHANNEL_CRUD         = "crud"
+	CHANNEL_WEBHOOK      = "webhook"
+	CHANNEL_DOMAIN_EVENT = "domain_event"
+)
+
+// Initialize creates the channels with room for size events each, before any event is pushed
+func Initialize(size int) {
+	crudEventChannel = make(chan common.Event, size)
+	webhookEventChannel = make(chan common.Event, size)
+	domainEventChannel = make(chan common.Event, size)
+
+	metrics.RegisterChannel(CHANNEL_CRUD, crudEventChannel)
+	metrics.RegisterChannel(CHANNEL_WEBHOOK, webhookEventChannel)
+	metrics.RegisterChannel(CHANNEL_DOMAIN_EVENT, domainEventChannel)
+
+	// Events are dropped once a channel is full, stop taking traffic before that happens
+	health.RegisterReadiness(
+		health.ChannelSaturation("channel crud", crudEventChannel, channelSaturationThreshold),
+		health.ChannelSaturation("channel webhook", webhookEventChannel, channelSaturationThreshold),
+		health.ChannelSaturation("channel domain_event", domainEventChannel, channelSaturationThreshold),
+	)
+}
 
 // Function to push data to the channel
-func PushToCRUDChannel(event common.Event) {
+func PushToCRUDChannel(event common.Event) bool {
 	select {
 	case crudEventChannel <- event:
 		// Successfully pushed
+		return true
 	default:
 		// Channel is full, log or handle overflow
-		log.Println("Channel is full, dropping data for event:", event)
+		metrics.ChannelEventsDropped.WithLabelValues(CHANNEL_CRUD).Inc()
+		slog.Error("CRUD channel is full, dropping event", "event_id", event.ID, "entity_name", event.EntityName, "type", event.Type, "request_id", event.RequestID)
+		return false
 	}
 }
 
@@ -24,3 +63,55 @@ func PushToCRUDChannel(event common.Event) {
 func GetCRUDEventChannel() chan common.Event {
 	return crudEventChannel
 }
+
+// Function to push data to the webhook channel
+func PushToWebhookChannel(event common.Event) bool {
+	select {
+	case webhookEventChannel <- event:
+		// Successfully pushed
+		return true
+	default:
+		// Channel is full, log or handle overflow
+		metrics.ChannelEventsDropped.WithLabelValues(CHANNEL_WEBHOOK).Inc()
+		slog.Error("webhook channel is full, dropping event", "event_id", event.ID, "entity_name", event.EntityName, "type", event.Type, "request_id", event.RequestID)
+		return false
+	}
+}
+
+// Function to get the webhook channel
+func GetWebhookEventChannel() chan common.Event {
+	return webhookEventChannel
+}
+
+// Function to push data to the domain event channel
+func PushToDomainEventChannel(event common.Event) bool {
+	select {
+	case domainEventChannel <- event:
+		// Successfully pushed
+		return true
+	default:
+		// Channel is full, log or handle overflow
+		metrics.ChannelEventsDropped.WithLabelValues(CHANNEL_DOMAIN_EVENT).Inc()
+		slog.Error("domain event channel is full, dropping event", "event_id", event.ID, "entity_name", event.EntityName, "type", event.Type, "request_id", event.RequestID)
+		return false
+	}
+}
+
+// Function to get the domain event channel
+func GetDomainEventChannel() chan common.Event {
+	return domainEventChannel
+}
+
+// Push pushes event to the channel named channel, it reports false when the channel is full or unknown
+func Push(channel string, event common.Event) bool {
+	switch channel {
+	case CHANNEL_CRUD:
+		return PushToCRUDChannel(event)
+	case CHANNEL_WEBHOOK:
+		return PushToWebhookChannel(event)
+	case CHANNEL_DOMAIN_EVENT:
+		return PushToDomainEventChannel(event)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventResponseDTO struct {
+	ID         string          `json:"id"`
+	EntityId   string          `json:"entityId"`
+	EntityName string          `json:"entityName"`
+	TenantId   string          `json:"tenantId,omitempty"`
+	Actor      string          `json:"actor,omitempty"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type DeadLetterEventResponseDTO struct {
+	ID         string          `json:"id"`
+	EventId    string          `json:"eventId"`
+	EntityId   string          `json:"entityId"`
+	EntityName string          `json:"entityName"`
+	Type       string          `json:"type"`
+	Channel    string          `json:"channel"`
+	Error      string          `json:"error"`
+	Attempts   int             `json:"attempts"`
+	Event      json.RawMessage `json:"event,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// ProblemDTO is an RFC 7807 problem details body, served as application/problem+json.
+// Code is a stable, machine readable error code, see apperror.
+type ProblemDTO struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+type WebhookSubscriptionResponseDTO struct {
+	ID          string   `json:"id"`
+	TargetUrl   string   `json:"targetUrl"`
+	EntityNames []string `json:"entityNames"`
+	EventTypes  []string `json:"eventTypes"`
+	Secret      string   `json:"secret,omitempty"`
+	Active      bool     `json:"active"`
+}
+
+type CreateWebhookSubscriptionDTO struct {
+	TargetUrl   string   `json:"targetUrl"`
+	EntityNames []string `json:"entityNames"`
+	EventTypes  []string `json:"eventTypes"`
+	Secret      string   `json:"secret"`
+}
+
+type UpdateWebhookSubscriptionDTO struct {
+	TargetUrl   string   `json:"targetUrl"`
+	EntityNames []string `json:"entityNames"`
+	EventTypes  []string `json:"eventTypes"`
+	Secret      string   `json:"secret"`
+	Active      bool     `json:"active"`
+}
+
+type WebhookDeliveryResponseDTO struct {
+	ID             string     `json:"id"`
+	SubscriptionId string     `json:"subscriptionId"`
+	EventId        string     `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"responseStatus"`
+	LastError      string     `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WebhookEventPayload is the JSON body posted to subscribers
+type WebhookEventPayload struct {
+	ID         string    `json:"id"`
+	EntityId   string    `json:"entityId"`
+	EntityName string    `json:"entityName"`
+	TenantId   string    `json:"tenantId,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+)
+
+// DeadLetterHandler lists the events the workers gave up on, replays and purges them
+type DeadLetterHandler interface {
+	ListDeadLetters(ctx *fiber.Ctx) error
+	ReplayDeadLetter(ctx *fiber.Ctx) error
+	DeleteDeadLetter(ctx *fiber.Ctx) error
+}
+
+type deadLetterHandler struct {
+	eventService service.EventService
+}
+
+func NewDeadLetterHandler(eventService service.EventService) DeadLetterHandler {
+	return &deadLetterHandler{
+		eventService: eventService,
+	}
+}
+
+// ListDeadLetters filters dead letters with query parameters like ListEvents, e.g.
+// /v1/dead-letters?channel=webhook&sort=created_at:desc&maxResults=50
+func (c *deadLetterHandler) ListDeadLetters(ctx *fiber.Ctx) error {
+	filterDTO, err := parseFilterQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadLetters, err := c.eventService.FindDeadLetters(ctx.UserContext(), filterDTO)
+	if err != nil {
+		return err
+	}
+
+	var responseDTOs = make([]dto.DeadLetterEventResponseDTO, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		responseDTOs = append(responseDTOs, toDeadLetterResponseDTO(deadLetter))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(responseDTOs))
+}
+
+// ReplayDeadLetter hands the event back to the worker it was taken from and removes the dead letter
+func (c *deadLetterHandler) ReplayDeadLetter(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	if err := c.eventService.ReplayDeadLetter(ctx.UserContext(), idParam); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(idParam))
+}
+
+func (c *deadLetterHandler) DeleteDeadLetter(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	if err := c.eventService.DeleteDeadLetter(ctx.UserContext(), idParam); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(common.DataDeletedSuccessfully))
+}
+
+func toDeadLetterResponseDTO(deadLetter *aggregate.DeadLetterEvent) dto.DeadLetterEventResponseDTO {
+	response := dto.DeadLetterEventResponseDTO{
+		ID:         deadLetter.ID,
+		EventId:    deadLetter.EventId,
+		EntityId:   deadLetter.EntityId,
+		EntityName: deadLetter.EntityName,
+		Type:       deadLetter.Type,
+		Channel:    deadLetter.Channel,
+		Error:      deadLetter.Error,
+		Attempts:   deadLetter.Attempts,
+		CreatedAt:  deadLetter.CreatedAt,
+	}
+	if deadLetter.Payload != "" {
+		response.Event = json.RawMessage(deadLetter.Payload)
+	}
+	return response
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ErrorHandler is the Fiber error handler. Every error returned by a handler or middleware is
+// written as an RFC 7807 problem: application errors with the status of their kind, Fiber errors
+// with their own status and any other error as a 500 that does not expose its message.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code := strings.ToUpper(strings.ReplaceAll(http.StatusText(fiberErr.Code), " ", "_"))
+		return WriteProblem(ctx, fiberErr.Code, code, fiberErr.Message)
+	}
+
+	appErr := apperror.From(err)
+	status := StatusOf(appErr)
+	if status >= http.StatusInternalServerError {
+		logger.FromContext(ctx.UserContext()).Error("request failed", "method", ctx.Method(), "path", ctx.Path(), "status", status, "error", err)
+		trace.SpanFromContext(ctx.UserContext()).RecordError(err)
+	}
+
+	return WriteProblem(ctx, status, appErr.Code, appErr.Message)
+}
+
+// StatusOf maps the kind of an application error to its HTTP status
+func StatusOf(err error) int {
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperror.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperror.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, apperror.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperror.ErrTimeout):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func WriteProblem(ctx *fiber.Ctx, status int, code string, detail string) error {
+	problem := dto.ProblemDTO{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: ctx.Path(),
+		Code:     code,
+	}
+	return ctx.Status(status).JSON(problem, ProblemContentType)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+)
+
+// EventHandler exposes the event store as a read-only audit API
+type EventHandler interface {
+	ListEvents(ctx *fiber.Ctx) error
+	FindEventWithFilter(ctx *fiber.Ctx) error
+	GetEventByID(ctx *fiber.Ctx) error
+}
+
+type eventHandler struct {
+	eventService service.EventService
+}
+
+func NewEventHandler(eventService service.EventService) EventHandler {
+	return &eventHandler{
+		eventService: eventService,
+	}
+}
+
+// ListEvents filters events with query parameters, e.g.
+// /v1/events?entity_name=User&type=ENTITY_DELETED&sort=created_at:desc&maxResults=50&offset=100
+// Every parameter other than sort, maxResults, offset and logic is an equality condition.
+func (c *eventHandler) ListEvents(ctx *fiber.Ctx) error {
+	filterDTO, err := parseFilterQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := c.eventService.FindWithFilter(ctx.UserContext(), filterDTO)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(toEventResponseDTOArray(events)))
+}
+
+func (c *eventHandler) FindEventWithFilter(ctx *fiber.Ctx) error {
+	var filterDTO common.FilterQuery
+
+	if err := ctx.BodyParser(&filterDTO); err != nil {
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
+	}
+
+	events, err := c.eventService.FindWithFilter(ctx.UserContext(), filterDTO)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(toEventResponseDTOArray(events)))
+}
+
+func (c *eventHandler) GetEventByID(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	event, err := c.eventService.GetById(ctx.UserContext(), idParam)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(toEventResponseDTO(event)))
+}
+
+// parseFilterQuery builds a FilterQuery from the request's query parameters
+func parseFilterQuery(ctx *fiber.Ctx) (common.FilterQuery, error) {
+	var filterQuery common.FilterQuery
+
+	for key, value := range ctx.Queries() {
+		switch key {
+		case "maxResults":
+			maxResults, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return filterQuery, apperror.Validation("invalid maxResults: %s", value)
+			}
+			filterQuery.MaxResults = uint(maxResults)
+		case "offset":
+			offset, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return filterQuery, apperror.Validation("invalid offset: %s", value)
+			}
+			filterQuery.Offset = uint(offset)
+		case "logic":
+			filterQuery.Logic = value
+		case "sort":
+			// sort=created_at:desc,entity_name:asc
+			for _, each := range strings.Split(value, ",") {
+				column, direction, _ := strings.Cut(each, ":")
+				sortDirection := common.SORT_ASC
+				if strings.EqualFold(direction, "desc") {
+					sortDirection = common.SORT_DESC
+				}
+				filterQuery.Sorts = append(filterQuery.Sorts, common.Sort{Key: column, Type: sortDirection})
+			}
+		default:
+			filterQuery.Conditions = append(filterQuery.Conditions, common.Condition{
+				Key:      key,
+				Value:    value,
+				Operator: common.CONDITION_EQ,
+			})
+		}
+	}
+
+	return filterQuery, nil
+}
+
+func toEventResponseDTO(event *aggregate.Event) dto.EventResponseDTO {
+	return dto.EventResponseDTO{
+		ID:         event.ID,
+		EntityId:   event.EntityId,
+		EntityName: event.EntityName,
+		TenantId:   event.TenantID,
+		Actor:      event.Actor,
+		Type:       event.Type,
+		Data:       json.RawMessage(event.Data),
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+func toEventResponseDTOArray(events []*aggregate.Event) []dto.EventResponseDTO {
+	var responseDTOs = make([]dto.EventResponseDTO, 0, len(events))
+	for _, event := range events {
+		responseDTOs = append(responseDTOs, toEventResponseDTO(event))
+	}
+	return responseDTOs
+}
@@ -8,6 +8,9 @@ import (
 
 type Handlers struct {
 	TemplateEntityHandler TemplateEntityHandler
+	EventHandler          EventHandler
+	WebhookHandler        WebhookHandler
+	DeadLetterHandler     DeadLetterHandler
 }
 
 var (
@@ -20,6 +23,9 @@ func GetHandlers() *Handlers {
 		var AllServices = service.GetServices()
 		allHandlers = &Handlers{
 			TemplateEntityHandler: NewTemplateEntityHandler(AllServices.TemplateEntityService),
+			EventHandler:          NewEventHandler(AllServices.EventService),
+			WebhookHandler:        NewWebhookHandler(AllServices.WebhookService),
+			DeadLetterHandler:     NewDeadLetterHandler(AllServices.EventService),
 		}
 	})
 	return allHandlers
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/health"
+)
+
+// Livez answers 200 while the process makes progress and 503 once a liveness check fails,
+// with the result of every check in the body
+func Livez(ctx *fiber.Ctx) error {
+	return healthResponse(ctx, health.Liveness(ctx.UserContext()))
+}
+
+// Readyz answers 200 while the service can take traffic and 503 when one of its dependencies
+// is unavailable or it is shutting down
+func Readyz(ctx *fiber.Ctx) error {
+	return healthResponse(ctx, health.Readiness(ctx.UserContext()))
+}
+
+func healthResponse(ctx *fiber.Ctx, report health.Report) error {
+	status := fiber.StatusOK
+	if !report.Up() {
+		status = fiber.StatusServiceUnavailable
+	}
+	return ctx.Status(status).JSON(report)
+}
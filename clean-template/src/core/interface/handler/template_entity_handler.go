@@ -2,11 +2,13 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nanda03dev/go-ms-template/src/common"
 	"github.com/nanda03dev/go-ms-template/src/core/application/service"
 	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
 	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
 )
 
@@ -16,6 +18,8 @@ type TemplateEntityHandler interface {
 	FindTemplateEntityWithFilter(ctx *fiber.Ctx) error
 	UpdateTemplateEntityById(ctx *fiber.Ctx) error
 	DeleteTemplateEntityById(ctx *fiber.Ctx) error
+	RestoreTemplateEntityById(ctx *fiber.Ctx) error
+	GetTemplateEntityHistory(ctx *fiber.Ctx) error
 }
 
 type templateEntityHandler struct {
@@ -32,24 +36,37 @@ func (c *templateEntityHandler) CreateTemplateEntity(ctx *fiber.Ctx) error {
 	var templateEntityDTO dto.CreateTemplateEntityDTO
 
 	if err := ctx.BodyParser(&templateEntityDTO); err != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(err))
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
 	}
 
-	result, err := c.templateEntityService.Create(templateEntityDTO)
-
+	result, err := c.templateEntityService.Create(ctx.UserContext(), templateEntityDTO)
 	if err != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(err))
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(result)))
 }
 
+// GetTemplateEntityByID returns the current templateEntity, or with ?as_of=<RFC3339 time> the state it had then
 func (c *templateEntityHandler) GetTemplateEntityByID(ctx *fiber.Ctx) error {
 	idParam := ctx.Params("id")
 
-	templateEntity, err := c.templateEntityService.GetById(idParam)
+	if asOfParam := ctx.Query("as_of"); asOfParam != "" {
+		asOf, err := time.Parse(time.RFC3339, asOfParam)
+		if err != nil {
+			return apperror.Validation(common.InvalidAsOfError).Wrap(err)
+		}
+
+		templateEntity, err := c.templateEntityService.GetByIdAsOf(ctx.UserContext(), idParam, asOf)
+		if err != nil {
+			return err
+		}
+		return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(templateEntity)))
+	}
+
+	templateEntity, err := c.templateEntityService.GetById(ctx.UserContext(), idParam)
 	if err != nil {
-		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse(common.TemplateEntityNotFoundError))
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(templateEntity)))
@@ -59,12 +76,12 @@ func (c *templateEntityHandler) FindTemplateEntityWithFilter(ctx *fiber.Ctx) err
 	var filterDTO common.FilterQuery
 
 	if err := ctx.BodyParser(&filterDTO); err != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(err))
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
 	}
 
-	templateEntitys, err := c.templateEntityService.FindWithFilter(filterDTO)
+	templateEntitys, err := c.templateEntityService.FindWithFilter(ctx.UserContext(), filterDTO)
 	if err != nil {
-		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse(common.TemplateEntityNotFoundError))
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTOArray(templateEntitys)))
@@ -72,22 +89,16 @@ func (c *templateEntityHandler) FindTemplateEntityWithFilter(ctx *fiber.Ctx) err
 
 func (c *templateEntityHandler) UpdateTemplateEntityById(ctx *fiber.Ctx) error {
 	idParam := ctx.Params("id")
-	_, err := c.templateEntityService.GetById(idParam)
-
-	if err != nil {
-		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse(common.TemplateEntityNotFoundError))
-	}
 
 	var templateEntityDTO dto.UpdateTemplateEntityDTO
 
 	if err := ctx.BodyParser(&templateEntityDTO); err != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(err))
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
 	}
 
-	result, err := c.templateEntityService.Update(idParam, templateEntityDTO)
-
+	result, err := c.templateEntityService.Update(ctx.UserContext(), idParam, templateEntityDTO)
 	if err != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(err))
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(result)))
@@ -96,19 +107,34 @@ func (c *templateEntityHandler) UpdateTemplateEntityById(ctx *fiber.Ctx) error {
 func (c *templateEntityHandler) DeleteTemplateEntityById(ctx *fiber.Ctx) error {
 	idParam := ctx.Params("id")
 
-	_, err := c.templateEntityService.GetById(idParam)
+	if err := c.templateEntityService.Delete(ctx.UserContext(), idParam); err != nil {
+		return err
+	}
 
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(common.DataDeletedSuccessfully))
+}
+
+func (c *templateEntityHandler) RestoreTemplateEntityById(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	templateEntity, err := c.templateEntityService.Restore(ctx.UserContext(), idParam)
 	if err != nil {
-		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse(common.TemplateEntityNotFoundError))
+		return err
 	}
 
-	deleteErr := c.templateEntityService.Delete(idParam)
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(templateEntity)))
+}
 
-	if deleteErr != nil {
-		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse(common.ErrorDeletingData))
+// GetTemplateEntityHistory returns the stored events of a templateEntity, oldest first
+func (c *templateEntityHandler) GetTemplateEntityHistory(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	events, err := c.templateEntityService.GetHistory(ctx.UserContext(), idParam)
+	if err != nil {
+		return err
 	}
 
-	return ctx.Status(http.StatusOK).JSON(SuccessResponse(common.DataDeletedSuccessfully))
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(toEventResponseDTOArray(events)))
 }
 
 // Helper function to convert Entity to TemplateEntityResponseDTO
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/auth"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/entity"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/repository"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/middleware"
+)
+
+// fakeTemplateEntityRepository reads events the way the tenant scope does: events are tenant
+// aware, so they are narrowed to the tenant of the context only when it has one
+type fakeTemplateEntityRepository struct {
+	repository.TemplateEntityRepository
+	events []*aggregate.Event
+}
+
+func (r *fakeTemplateEntityRepository) eventsOf(ctx context.Context, id string, at time.Time) []*aggregate.Event {
+	tenantId, scoped := common.TenantFromContext(ctx)
+	var result []*aggregate.Event
+	for _, event := range r.events {
+		if event.EntityId == id && !event.CreatedAt.After(at) && (!scoped || event.TenantID == tenantId) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func (r *fakeTemplateEntityRepository) FindHistory(ctx context.Context, id string) ([]*aggregate.Event, error) {
+	return r.eventsOf(ctx, id, time.Now()), nil
+}
+
+func (r *fakeTemplateEntityRepository) AsOf(ctx context.Context, id string, at time.Time) (*aggregate.TemplateEntity, error) {
+	events := r.eventsOf(ctx, id, at)
+	if len(events) == 0 {
+		return nil, apperror.NotFound(common.RecordNotFoundError)
+	}
+	return &aggregate.TemplateEntity{ID: events[len(events)-1].EntityId}, nil
+}
+
+func TestTemplateEntityEventReadsStayInTheirTenant(t *testing.T) {
+	if _, scoped := any(&entity.TemplateEntity{}).(common.TenantScopedModel); !scoped {
+		t.Skip("templateEntity is not multi tenant")
+	}
+
+	// Without authentication every principal may read every record, only the tenant guards them
+	auth.Configure(auth.Settings{Disabled: true})
+	repository := &fakeTemplateEntityRepository{events: []*aggregate.Event{{
+		ID:         "event-1",
+		EntityId:   "1",
+		EntityName: string(entity.TemplateEntityEntityName),
+		TenantID:   "tenant-a",
+		Type:       string(common.ENTITY_CREATED),
+		CreatedAt:  time.Now().Add(-time.Hour),
+	}}}
+	handler := NewTemplateEntityHandler(service.NewTemplateEntityService(repository))
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(middleware.Authenticate(middleware.AuthOptions{}))
+	app.Get("/:id", handler.GetTemplateEntityByID)
+	app.Get("/:id/history", handler.GetTemplateEntityHistory)
+
+	history := "/1/history"
+	asOf := "/1?as_of=" + url.QueryEscape(time.Now().Format(time.RFC3339))
+
+	tests := []struct {
+		name       string
+		path       string
+		tenant     string
+		wantStatus int
+		wantCode   string
+		wantEvents int
+	}{
+		{name: "history without a tenant", path: history, wantStatus: http.StatusBadRequest, wantCode: apperror.CODE_TENANT_REQUIRED},
+		{name: "as of without a tenant", path: asOf, wantStatus: http.StatusBadRequest, wantCode: apperror.CODE_TENANT_REQUIRED},
+		{name: "history of another tenant", path: history, tenant: "tenant-b", wantStatus: http.StatusOK},
+		{name: "as of another tenant", path: asOf, tenant: "tenant-b", wantStatus: http.StatusNotFound},
+		{name: "history of the tenant", path: history, tenant: "tenant-a", wantStatus: http.StatusOK, wantEvents: 1},
+		{name: "as of the tenant", path: asOf, tenant: "tenant-a", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.tenant != "" {
+				request.Header.Set(middleware.TenantHeader, tt.tenant)
+			}
+			response, err := app.Test(request)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantCode != "" {
+				var problem dto.ProblemDTO
+				if err := json.NewDecoder(response.Body).Decode(&problem); err != nil {
+					t.Fatalf("failed to decode the problem: %v", err)
+				}
+				if problem.Code != tt.wantCode {
+					t.Errorf("code = %s, want %s", problem.Code, tt.wantCode)
+				}
+				return
+			}
+			if tt.path != history {
+				return
+			}
+
+			var body struct {
+				Data []json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode the history: %v", err)
+			}
+			if len(body.Data) != tt.wantEvents {
+				t.Errorf("history has %d events, want %d", len(body.Data), tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/service"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/aggregate"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/interface/dto"
+)
+
+type WebhookHandler interface {
+	CreateWebhook(ctx *fiber.Ctx) error
+	GetWebhookByID(ctx *fiber.Ctx) error
+	FindWebhookWithFilter(ctx *fiber.Ctx) error
+	UpdateWebhookById(ctx *fiber.Ctx) error
+	DeleteWebhookById(ctx *fiber.Ctx) error
+	FindWebhookDeliveries(ctx *fiber.Ctx) error
+	RedeliverWebhook(ctx *fiber.Ctx) error
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (c *webhookHandler) CreateWebhook(ctx *fiber.Ctx) error {
+	var webhookDTO dto.CreateWebhookSubscriptionDTO
+
+	if err := ctx.BodyParser(&webhookDTO); err != nil {
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
+	}
+
+	result, err := c.webhookService.Create(ctx.UserContext(), webhookDTO)
+	if err != nil {
+		return err
+	}
+
+	// The secret is only returned once, when the subscription is created
+	response := c.toResponseDTO(result)
+	response.Secret = result.Secret
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(response))
+}
+
+func (c *webhookHandler) GetWebhookByID(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	subscription, err := c.webhookService.GetById(ctx.UserContext(), idParam)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(subscription)))
+}
+
+func (c *webhookHandler) FindWebhookWithFilter(ctx *fiber.Ctx) error {
+	var filterDTO common.FilterQuery
+
+	if err := ctx.BodyParser(&filterDTO); err != nil {
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
+	}
+
+	subscriptions, err := c.webhookService.FindWithFilter(ctx.UserContext(), filterDTO)
+	if err != nil {
+		return err
+	}
+
+	var responseDTOs = make([]dto.WebhookSubscriptionResponseDTO, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responseDTOs = append(responseDTOs, c.toResponseDTO(subscription))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(responseDTOs))
+}
+
+func (c *webhookHandler) UpdateWebhookById(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	var webhookDTO dto.UpdateWebhookSubscriptionDTO
+
+	if err := ctx.BodyParser(&webhookDTO); err != nil {
+		return apperror.Validation(common.InvalidRequestError).Wrap(err)
+	}
+
+	result, err := c.webhookService.Update(ctx.UserContext(), idParam, webhookDTO)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toResponseDTO(result)))
+}
+
+func (c *webhookHandler) DeleteWebhookById(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	if err := c.webhookService.Delete(ctx.UserContext(), idParam); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(common.DataDeletedSuccessfully))
+}
+
+func (c *webhookHandler) FindWebhookDeliveries(ctx *fiber.Ctx) error {
+	idParam := ctx.Params("id")
+
+	var filterDTO common.FilterQuery
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&filterDTO); err != nil {
+			return apperror.Validation(common.InvalidRequestError).Wrap(err)
+		}
+	}
+
+	deliveries, err := c.webhookService.FindDeliveries(ctx.UserContext(), idParam, filterDTO)
+	if err != nil {
+		return err
+	}
+
+	var responseDTOs = make([]dto.WebhookDeliveryResponseDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responseDTOs = append(responseDTOs, c.toDeliveryResponseDTO(delivery))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(responseDTOs))
+}
+
+// RedeliverWebhook makes one more delivery attempt; the outcome is reported in the delivery status
+func (c *webhookHandler) RedeliverWebhook(ctx *fiber.Ctx) error {
+	deliveryIdParam := ctx.Params("deliveryId")
+
+	delivery, err := c.webhookService.Redeliver(ctx.UserContext(), deliveryIdParam)
+	if delivery == nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(SuccessResponse(c.toDeliveryResponseDTO(delivery)))
+}
+
+// Helper function to convert WebhookSubscription to WebhookSubscriptionResponseDTO, without its secret
+func (c *webhookHandler) toResponseDTO(subscription *aggregate.WebhookSubscription) dto.WebhookSubscriptionResponseDTO {
+	return dto.WebhookSubscriptionResponseDTO{
+		ID:          subscription.ID,
+		TargetUrl:   subscription.TargetUrl,
+		EntityNames: subscription.EntityNames,
+		EventTypes:  subscription.EventTypes,
+		Active:      subscription.Active,
+	}
+}
+
+func (c *webhookHandler) toDeliveryResponseDTO(delivery *aggregate.WebhookDelivery) dto.WebhookDeliveryResponseDTO {
+	return dto.WebhookDeliveryResponseDTO{
+		ID:             delivery.ID,
+		SubscriptionId: delivery.SubscriptionId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/auth"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+)
+
+const (
+	PrincipalLocalsKey = "principal"
+	APIKeyHeader       = "X-API-Key"
+)
+
+// AuthOptions configure authentication of one route group.
+type AuthOptions struct {
+	// Methods accepted on the group, both JWT and API keys when empty
+	Methods []common.AuthMethod
+	// Optional lets requests without credentials through without a principal,
+	// invalid credentials are still rejected
+	Optional bool
+}
+
+// Authenticate resolves the caller from an "Authorization: Bearer <jwt>" or "X-API-Key" header
+// and stores it as a *common.Principal in ctx.Locals(PrincipalLocalsKey) and the user context.
+// The request's tenant is resolved after, see resolveTenant.
+func Authenticate(options AuthOptions) fiber.Handler {
+	authenticator := auth.GetAuthenticator()
+	methods := options.Methods
+	if len(methods) == 0 {
+		methods = []common.AuthMethod{common.AUTH_METHOD_JWT, common.AUTH_METHOD_API_KEY}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if authenticator.Settings.Disabled {
+			return next(c, &common.Principal{Subject: "anonymous", Method: common.AUTH_METHOD_NONE})
+		}
+
+		if token, ok := bearerToken(c); ok && slices.Contains(methods, common.AUTH_METHOD_JWT) {
+			principal, err := authenticator.JWT.Verify(token)
+			if err != nil {
+				return unauthorized(c, "invalid token")
+			}
+			return next(c, principal)
+		}
+
+		if key := c.Get(APIKeyHeader); key != "" && slices.Contains(methods, common.AUTH_METHOD_API_KEY) {
+			principal, err := authenticator.APIKeys.Verify(key)
+			if err != nil {
+				return unauthorized(c, "invalid API key")
+			}
+			return next(c, principal)
+		}
+
+		if options.Optional {
+			return resolveTenant(c, nil)
+		}
+
+		return unauthorized(c, "missing credentials")
+	}
+}
+
+// GetPrincipal returns the caller resolved by Authenticate
+func GetPrincipal(c *fiber.Ctx) (*common.Principal, bool) {
+	principal, ok := c.Locals(PrincipalLocalsKey).(*common.Principal)
+	return principal, ok && principal != nil
+}
+
+func next(c *fiber.Ctx, principal *common.Principal) error {
+	c.Locals(PrincipalLocalsKey, principal)
+	ctx := common.WithPrincipal(c.UserContext(), principal)
+	c.SetUserContext(logger.With(ctx, "subject", principal.Subject))
+	return resolveTenant(c, principal)
+}
+
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:]), true
+	}
+	return "", false
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	return apperror.Unauthorized("%s", message)
+}
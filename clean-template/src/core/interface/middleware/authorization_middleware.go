@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/application/authorization"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+)
+
+// Authorize rejects requests whose principal has no access at all to the operation of an entity.
+// Owner only access is let through, the service checks the ownership of the records involved.
+// It must run after Authenticate.
+func Authorize(policy authorization.Policy, operation authorization.Operation) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if policy.Access(c.UserContext(), operation) == authorization.ACCESS_DENIED {
+			return apperror.Forbidden(common.ForbiddenError)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
+)
+
+// Metrics records the duration and status of every request per route. Routes are labelled with
+// their pattern, e.g. /api/v1/user/:id, so ids do not multiply the series.
+// It must run before RequestLogger, which writes errors, for the recorded status to be final.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		method := c.Method()
+		route := c.Route().Path
+		status := strconv.Itoa(c.Response().StatusCode())
+
+		metrics.HttpRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+		metrics.HttpRequestsTotal.WithLabelValues(method, route, status).Inc()
+		return err
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+)
+
+// ReadYourWritesHeader set to true sends the reads of a request to the primary database, for
+// clients that must see a write they just made
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
+// ReadYourWrites honours ReadYourWritesHeader, see db.WithPrimary
+func ReadYourWrites() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if readYourWrites, _ := strconv.ParseBool(c.Get(ReadYourWritesHeader)); readYourWrites {
+			c.SetUserContext(db.WithPrimary(c.UserContext()))
+		}
+		return c.Next()
+	}
+}
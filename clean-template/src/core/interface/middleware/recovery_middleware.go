@@ -1,24 +1,20 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
 )
 
 // Custom recovery middleware for Fiber
 func RecoveryMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) (err error) {
 		// Defer the recovery logic to catch any panics
 		defer func() {
-			if err := recover(); err != nil {
-				// Log the panic error for further investigation
-				log.Printf("Recovered from panic: %v", err)
-
-				// Return a generic Internal Server Error response
-				c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Internal Server Error",
-				})
+			if recovered := recover(); recovered != nil {
+				// The error handler logs the panic and answers with a generic Internal Server Error problem
+				err = apperror.Internal(fmt.Errorf("panic: %v", recovered))
 			}
 		}()
 
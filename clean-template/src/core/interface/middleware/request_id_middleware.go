@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+	"github.com/nanda03dev/go-ms-template/src/helper"
+)
+
+const (
+	RequestIDLocalsKey = "requestId"
+	RequestIDHeader    = fiber.HeaderXRequestID
+)
+
+// maxRequestIdLength bounds ids taken from the X-Request-ID header, longer ids are replaced
+const maxRequestIdLength = 128
+
+// RequestID keeps the X-Request-ID of the caller, or generates one, and echoes it in the response.
+// The id is stored in the request's user context and added to its logger as "request_id".
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestId := c.Get(RequestIDHeader)
+		if !isValidRequestId(requestId) {
+			requestId = helper.Generate16DigitUUID()
+		}
+
+		c.Set(RequestIDHeader, requestId)
+		c.Locals(RequestIDLocalsKey, requestId)
+
+		ctx := common.WithRequestID(c.UserContext(), requestId)
+		c.SetUserContext(logger.With(ctx, "request_id", requestId))
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the id of the request
+func GetRequestID(c *fiber.Ctx) string {
+	requestId, _ := c.Locals(RequestIDLocalsKey).(string)
+	return requestId
+}
+
+// isValidRequestId accepts printable ASCII ids, so a caller cannot forge log lines
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, char := range requestId {
+		if char < 0x21 || char > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nanda03dev/go-ms-template/src/common"
+	"github.com/nanda03dev/go-ms-template/src/core/domain/apperror"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/db"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+)
+
+const (
+	TenantLocalsKey = "tenant"
+	TenantHeader    = "X-Tenant-ID"
+)
+
+// resolveTenant binds the request to the tenant of the principal's credentials or, for credentials
+// not bound to a tenant, to the X-Tenant-ID header. A header naming another tenant is refused.
+func resolveTenant(c *fiber.Ctx, principal *common.Principal) error {
+	tenantId := c.Get(TenantHeader)
+	if principal != nil && principal.TenantID != "" {
+		if tenantId != "" && tenantId != principal.TenantID {
+			return apperror.Forbidden("credentials are not valid for tenant %s", tenantId)
+		}
+		tenantId = principal.TenantID
+	}
+
+	if tenantId == "" {
+		return c.Next()
+	}
+
+	if err := db.ValidateTenantId(tenantId); err != nil {
+		return err
+	}
+
+	c.Locals(TenantLocalsKey, tenantId)
+	ctx := common.WithTenant(c.UserContext(), tenantId)
+	c.SetUserContext(logger.With(ctx, "tenant_id", tenantId))
+	return c.Next()
+}
+
+// GetTenant returns the tenant resolved for the request
+func GetTenant(c *fiber.Ctx) (string, bool) {
+	tenantId, ok := c.Locals(TenantLocalsKey).(string)
+	return tenantId, ok && tenantId != ""
+}
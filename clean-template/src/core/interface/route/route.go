@@ -2,29 +2,81 @@ package route
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/healthcheck"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/nanda03dev/go-ms-template/src/core/application/authorization"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/config"
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/metrics"
 	"github.com/nanda03dev/go-ms-template/src/core/interface/handler"
 	"github.com/nanda03dev/go-ms-template/src/core/interface/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func InitializeRoutes(fiberApp *fiber.App) {
-	// Apply the global recovery middleware first
+func InitializeRoutes(fiberApp *fiber.App, server config.ServerConfig) {
+	// The request id comes first so every log line of the request carries it
+	fiberApp.Use(middleware.RequestID())
+	fiberApp.Use(middleware.Tracing())
+	fiberApp.Use(middleware.Metrics())
+	fiberApp.Use(middleware.RequestLogger())
+	// Recovery turns panics into errors, answered by the error handler and logged with the request
 	fiberApp.Use(middleware.RecoveryMiddleware())
-	fiberApp.Use(healthcheck.New())
-	fiberApp.Use(logger.New())
+	// Cancels the context handed to services and repositories once the request timeout passes
+	fiberApp.Use(middleware.Timeout(server.RequestTimeout))
+	// Reads go to a replica unless the request asks for the primary with X-Read-Your-Writes: true
+	fiberApp.Use(middleware.ReadYourWrites())
+
+	// Probes, answered without authentication
+	fiberApp.Get("/livez", handler.Livez)
+	fiberApp.Get("/readyz", handler.Readyz)
+
+	// Prometheus metrics, scraped without authentication
+	fiberApp.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
 
 	api := fiberApp.Group("/api")
 
+	// Authentication is set per route group, e.g. middleware.AuthOptions{Methods: []common.AuthMethod{common.AUTH_METHOD_API_KEY}}
+	// to accept API keys only, or middleware.AuthOptions{Optional: true} for public reads
+	authenticated := middleware.Authenticate(middleware.AuthOptions{})
+
 	AllHandlers := handler.GetHandlers()
 
+	// The event store, webhook and dead letter API'S span every entity, see authorization.AdminPolicy
+	adminPolicy := authorization.AdminPolicy
+
+	// Event store (audit) API'S, read only
+	eventHandler := AllHandlers.EventHandler
+	eventV1Routes := api.Group("/v1/events", authenticated)
+	eventV1Routes.Get("/", middleware.Authorize(adminPolicy, authorization.OPERATION_LIST), eventHandler.ListEvents)
+	eventV1Routes.Post("/filter", middleware.Authorize(adminPolicy, authorization.OPERATION_LIST), eventHandler.FindEventWithFilter)
+	eventV1Routes.Get("/:id", middleware.Authorize(adminPolicy, authorization.OPERATION_READ), eventHandler.GetEventByID)
+
+	// Webhook subscription API'S
+	webhookHandler := AllHandlers.WebhookHandler
+	webhookV1Routes := api.Group("/v1/webhooks", authenticated)
+	webhookV1Routes.Post("/", middleware.Authorize(adminPolicy, authorization.OPERATION_CREATE), webhookHandler.CreateWebhook)
+	webhookV1Routes.Post("/filter", middleware.Authorize(adminPolicy, authorization.OPERATION_LIST), webhookHandler.FindWebhookWithFilter)
+	webhookV1Routes.Post("/deliveries/:deliveryId/redeliver", middleware.Authorize(adminPolicy, authorization.OPERATION_UPDATE), webhookHandler.RedeliverWebhook)
+	webhookV1Routes.Get("/:id", middleware.Authorize(adminPolicy, authorization.OPERATION_READ), webhookHandler.GetWebhookByID)
+	webhookV1Routes.Get("/:id/deliveries", middleware.Authorize(adminPolicy, authorization.OPERATION_LIST), webhookHandler.FindWebhookDeliveries)
+	webhookV1Routes.Put("/:id", middleware.Authorize(adminPolicy, authorization.OPERATION_UPDATE), webhookHandler.UpdateWebhookById)
+	webhookV1Routes.Delete("/:id", middleware.Authorize(adminPolicy, authorization.OPERATION_DELETE), webhookHandler.DeleteWebhookById)
+
+	// Dead letter API'S, the events the workers gave up on
+	deadLetterHandler := AllHandlers.DeadLetterHandler
+	deadLetterV1Routes := api.Group("/v1/dead-letters", authenticated)
+	deadLetterV1Routes.Get("/", middleware.Authorize(adminPolicy, authorization.OPERATION_LIST), deadLetterHandler.ListDeadLetters)
+	deadLetterV1Routes.Post("/:id/replay", middleware.Authorize(adminPolicy, authorization.OPERATION_UPDATE), deadLetterHandler.ReplayDeadLetter)
+	deadLetterV1Routes.Delete("/:id", middleware.Authorize(adminPolicy, authorization.OPERATION_DELETE), deadLetterHandler.DeleteDeadLetter)
+
 	// TemplateEntity CRUD API'S
 	templateEntityHandler := AllHandlers.TemplateEntityHandler
-	templateEntityV1Routes := api.Group("/v1/templateEntity")
-	templateEntityV1Routes.Post("/", templateEntityHandler.CreateTemplateEntity)
-	templateEntityV1Routes.Post("/filter", templateEntityHandler.FindTemplateEntityWithFilter)
-	templateEntityV1Routes.Get("/:id", templateEntityHandler.GetTemplateEntityByID)
-	templateEntityV1Routes.Put("/:id", templateEntityHandler.UpdateTemplateEntityById)
-	templateEntityV1Routes.Delete("/:id", templateEntityHandler.DeleteTemplateEntityById)
+	templateEntityV1Routes := api.Group("/v1/templateEntity", authenticated)
+	templateEntityPolicy := authorization.TemplateEntityPolicy
+	templateEntityV1Routes.Post("/", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_CREATE), templateEntityHandler.CreateTemplateEntity)
+	templateEntityV1Routes.Post("/filter", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_LIST), templateEntityHandler.FindTemplateEntityWithFilter)
+	templateEntityV1Routes.Get("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_READ), templateEntityHandler.GetTemplateEntityByID)
+	templateEntityV1Routes.Put("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_UPDATE), templateEntityHandler.UpdateTemplateEntityById)
+	templateEntityV1Routes.Delete("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_DELETE), templateEntityHandler.DeleteTemplateEntityById)
+	templateEntityV1Routes.Post("/:id/restore", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_DELETE), templateEntityHandler.RestoreTemplateEntityById)
+	templateEntityV1Routes.Get("/:id/history", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_READ), templateEntityHandler.GetTemplateEntityHistory)
 
 }
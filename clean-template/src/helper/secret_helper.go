@@ -0,0 +1,15 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateSecret returns a random hex encoded secret of the given byte length
+func GenerateSecret(length int) (string, error) {
+	secret := make([]byte, length)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -28,11 +29,8 @@ func modifyFile(filePath string, entity Entity) error {
 		return ToUpdateRouterFile(filePath, entity)
 	}
 
-	if strings.Contains(filePath, "response_messages.go") {
-		return ToUpdateCommonResponseMessage(filePath, entity)
-	}
-
-	if strings.Contains(filePath, "entities.go") {
+	// The models list, not the model files of the entities
+	if filepath.Base(filePath) == "entity.go" {
 		return ToUpdateEntityFile(filePath, entity)
 	}
 
@@ -44,12 +42,15 @@ func ToUpdateRouterFile(filePath string, entity Entity) error {
 	var newLine = `
 	// TemplateEntity CRUD API'S
 	templateEntityHandler := AllHandlers.TemplateEntityHandler
-	templateEntityV1Routes := api.Group("/v1/templateEntity")
-	templateEntityV1Routes.Post("/", templateEntityHandler.CreateTemplateEntity)
-	templateEntityV1Routes.Get("/:id", templateEntityHandler.GetTemplateEntityByID)
-	templateEntityV1Routes.Post("/filter", templateEntityHandler.FindTemplateEntityWithFilter)
-	templateEntityV1Routes.Put("/:id", templateEntityHandler.UpdateTemplateEntityById)
-	templateEntityV1Routes.Delete("/:id", templateEntityHandler.DeleteTemplateEntityById)
+	templateEntityV1Routes := api.Group("/v1/templateEntity", authenticated)
+	templateEntityPolicy := authorization.TemplateEntityPolicy
+	templateEntityV1Routes.Post("/", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_CREATE), templateEntityHandler.CreateTemplateEntity)
+	templateEntityV1Routes.Get("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_READ), templateEntityHandler.GetTemplateEntityByID)
+	templateEntityV1Routes.Post("/filter", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_LIST), templateEntityHandler.FindTemplateEntityWithFilter)
+	templateEntityV1Routes.Put("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_UPDATE), templateEntityHandler.UpdateTemplateEntityById)
+	templateEntityV1Routes.Delete("/:id", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_DELETE), templateEntityHandler.DeleteTemplateEntityById)
+	templateEntityV1Routes.Post("/:id/restore", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_DELETE), templateEntityHandler.RestoreTemplateEntityById)
+	templateEntityV1Routes.Get("/:id/history", middleware.Authorize(templateEntityPolicy, authorization.OPERATION_READ), templateEntityHandler.GetTemplateEntityHistory)
 	`
 
 	newLine = replaceEntityName(newLine, entity)
@@ -65,34 +66,15 @@ func ToUpdateRouterFile(filePath string, entity Entity) error {
 	handlerLine := "templateEntityHandler := AllHandlers.TemplateEntityHandler"
 
 	if !strings.Contains(content, replaceEntityName(handlerLine, entity)) {
-		startKeyword := "func InitializeRoutes(fiberApp *fiber.App) {"
-		endKeyword := "}"
+		startKeyword := "func InitializeRoutes(fiberApp *fiber.App, server config.ServerConfig) {"
+		// The closing bracket of the function, route options may hold brackets themselves
+		endKeyword := "\n}"
 		content = AddNewLineToEnd(newLine, content, startKeyword, endKeyword, "", "")
 	}
 
 	return WriteFileInPath(filePath, content)
 }
 
-func ToUpdateCommonResponseMessage(filePath string, entity Entity) error {
-
-	var newLine = `TemplateEntityNotFoundError = "templateEntity not found"`
-	newLine = replaceEntityName(newLine, entity)
-
-	// Read the existing file content
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("error reading file %s: %v", filePath, err)
-	}
-
-	content := string(data)
-
-	startKeyword := "const ("
-	endKeyword := ")"
-	content = AddNewLineToEnd(newLine, content, startKeyword, endKeyword, fmt.Sprintf("\n //%s \n", ToUpperFirst(entity.EntityName)), "")
-
-	return WriteFileInPath(filePath, content)
-}
-
 func ToUpdateAppModuleFile(filePath string, entity Entity) error {
 	// Read the existing file content
 	data, err := os.ReadFile(filePath)
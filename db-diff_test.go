@@ -0,0 +1,410 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// driftedUsers returns a config of users and teams, and a database where teams was never created
+// and users drifted from the config
+func driftedUsers() (actual Schema, desired Schema) {
+	desired = schemaOf(
+		entityWith("user",
+			Field{FieldName: "email", Type: "string", Required: true, Unique: true},
+			Field{FieldName: "age", Type: "int"},
+			Field{FieldName: "team_id", Type: "string", References: "team"},
+		),
+		entityWith("team"),
+	)
+
+	users, _ := desired.table("users")
+	users = cloneSchema(Schema{Tables: []Table{users}}).Tables[0]
+	for index := range users.Columns {
+		switch users.Columns[index].Name {
+		case "age":
+			users.Columns[index].Type = "integer"
+		case "email":
+			users.Columns[index].NotNull = false
+		}
+	}
+	users.Columns = append(users.Columns, Column{Name: "legacy_code", Type: "varchar"})
+	users.Indexes = slices.DeleteFunc(users.Indexes, func(index Index) bool { return index.Name == "idx_users_email" })
+	users.Indexes = append(users.Indexes, Index{Name: "idx_users_legacy_code", Columns: []string{"legacy_code"}})
+	users.ForeignKeys = nil
+
+	return Schema{Tables: []Table{users}}, desired
+}
+
+func TestDescribeDifferences(t *testing.T) {
+	users := desiredTable(entityWith("user",
+		Field{FieldName: "email", Type: "string", Unique: true},
+		Field{FieldName: "team_id", Type: "string", References: "team"},
+	))
+	change := func(edit func(table *Table)) Schema {
+		table := cloneSchema(Schema{Tables: []Table{users}}).Tables[0]
+		edit(&table)
+		return Schema{Tables: []Table{table}}
+	}
+	desired := Schema{Tables: []Table{users}}
+
+	tests := []struct {
+		name   string
+		actual Schema
+		want   []string
+	}{
+		{
+			name:   "identical",
+			actual: desired,
+		},
+		{
+			name:   "table missing",
+			actual: Schema{},
+			want:   []string{"users: table is missing"},
+		},
+		{
+			name: "column missing",
+			actual: change(func(table *Table) {
+				table.Columns = slices.DeleteFunc(table.Columns, func(column Column) bool { return column.Name == "created_at" })
+			}),
+			want: []string{"users: column created_at timestamptz is missing"},
+		},
+		{
+			name:   "column type",
+			actual: change(func(table *Table) { table.Columns[1].Type = "varchar" }),
+			want:   []string{"users: column email is varchar, the config implies text"},
+		},
+		{
+			name:   "column nullability",
+			actual: change(func(table *Table) { table.Columns[1].NotNull = true }),
+			want:   []string{"users: column email is NOT NULL, the config implies nullable"},
+		},
+		{
+			name:   "column default",
+			actual: change(func(table *Table) { table.Columns[1].Default = "'none'" }),
+			want:   []string{"users: column email has default 'none', the config implies no default"},
+		},
+		{
+			name: "column not in the config",
+			actual: change(func(table *Table) {
+				table.Columns = append(table.Columns, Column{Name: "legacy_code", Type: "varchar", NotNull: true})
+			}),
+			want: []string{"users: column legacy_code varchar NOT NULL is not in the config"},
+		},
+		{
+			name: "index missing",
+			actual: change(func(table *Table) {
+				table.Indexes = slices.DeleteFunc(table.Indexes, func(index Index) bool { return index.Name == "idx_users_email" })
+			}),
+			want: []string{"users: index idx_users_email is missing"},
+		},
+		{
+			name:   "index differs",
+			actual: change(func(table *Table) { table.Indexes[0].Unique = false }),
+			want:   []string{"users: index idx_users_email differs: email, the config implies unique on email"},
+		},
+		{
+			name: "index not in the config",
+			actual: change(func(table *Table) {
+				table.Indexes = append(table.Indexes, Index{Name: "idx_users_lower_email", Columns: []string{"email", "id"}, Unique: true})
+			}),
+			want: []string{"users: index idx_users_lower_email (unique on email, id) is not in the config"},
+		},
+		{
+			name:   "foreign key missing",
+			actual: change(func(table *Table) { table.ForeignKeys = nil }),
+			want:   []string{"users: foreign key fk_users_team_id is missing"},
+		},
+		{
+			name:   "foreign key differs",
+			actual: change(func(table *Table) { table.ForeignKeys[0].RefTable = "squads" }),
+			want:   []string{"users: foreign key fk_users_team_id references squads (id), the config implies teams (id)"},
+		},
+		{
+			name: "foreign key not in the config",
+			actual: change(func(table *Table) {
+				table.ForeignKeys = append(table.ForeignKeys, ForeignKey{Name: "users_email_fkey", Column: "email", RefTable: "emails", RefColumn: "address"})
+			}),
+			want: []string{"users: foreign key users_email_fkey on email is not in the config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeDifferences(tt.actual, desired); !slices.Equal(got, tt.want) {
+				t.Errorf("describeDifferences =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestReconcileMigrations covers the migrations of -write, which take the database to the config
+func TestReconcileMigrations(t *testing.T) {
+	actual, desired := driftedUsers()
+	if differences := describeDifferences(actual, desired); len(differences) != 7 {
+		t.Fatalf("describeDifferences found %d differences, want 7:\n%s", len(differences), strings.Join(differences, "\n"))
+	}
+
+	migrations := planMigrations(actual, desired)
+	if names := migrationNames(migrations); !slices.Equal(names, []string{"create_teams", "alter_users"}) {
+		t.Fatalf("migrations = %v, want [create_teams alter_users]", names)
+	}
+	assertReversible(t, actual, desired, migrations)
+
+	reconciled := cloneSchema(actual)
+	for _, migration := range migrations {
+		for _, statement := range migration.up {
+			applyStatement(t, &reconciled, statement)
+		}
+	}
+	if differences := describeDifferences(reconciled, desired); len(differences) > 0 {
+		t.Errorf("the reconciled schema still differs:\n%s", strings.Join(differences, "\n"))
+	}
+}
+
+// fakeCatalog serves fixed catalog rows per table, as Postgres reports them
+type fakeCatalog struct {
+	tables map[string]catalogRows
+	err    error
+}
+
+type catalogRows struct {
+	columns     []columnRow
+	primaryKey  []string
+	indexes     []indexRow
+	foreignKeys []ForeignKey
+}
+
+func (c fakeCatalog) columns(table string) ([]columnRow, error) {
+	return c.tables[table].columns, c.err
+}
+
+func (c fakeCatalog) primaryKey(table string) ([]string, error) {
+	return c.tables[table].primaryKey, nil
+}
+
+func (c fakeCatalog) indexes(table string) ([]indexRow, error) {
+	return c.tables[table].indexes, nil
+}
+
+func (c fakeCatalog) foreignKeys(table string) ([]ForeignKey, error) {
+	return c.tables[table].foreignKeys, nil
+}
+
+func TestIntrospect(t *testing.T) {
+	desired := desiredSchema(Config{Entities: []Entity{
+		{EntityName: "user", MultiTenant: true, Fields: []Field{
+			{FieldName: "email", Type: "string", Required: true, Unique: true},
+			{FieldName: "tags", Type: "[]string"},
+			{FieldName: "balance", Type: "float64"},
+			{FieldName: "team_id", Type: "string", References: "team"},
+		}},
+		entityWith("team"),
+	}})
+
+	emptyText := "''::text"
+	column := func(name string, dataType string, udtName string, nullable string) columnRow {
+		return columnRow{ColumnName: name, DataType: dataType, UdtName: udtName, IsNullable: nullable}
+	}
+	tenantId := column("tenant_id", "text", "text", "NO")
+	tenantId.ColumnDefault = &emptyText
+
+	// The users table as the generated migrations create it, teams was never created
+	database := fakeCatalog{tables: map[string]catalogRows{
+		"users": {
+			columns: []columnRow{
+				column("id", "text", "text", "NO"),
+				column("email", "text", "text", "NO"),
+				column("tags", "ARRAY", "_text", "YES"),
+				column("balance", "numeric", "numeric", "YES"),
+				column("team_id", "text", "text", "YES"),
+				tenantId,
+				column("created_at", "timestamp with time zone", "timestamptz", "YES"),
+				column("updated_at", "timestamp with time zone", "timestamptz", "YES"),
+				column("deleted_at", "timestamp with time zone", "timestamptz", "YES"),
+			},
+			primaryKey: []string{"id"},
+			indexes: []indexRow{
+				{IndexName: "idx_users_deleted_at", ColumnName: "deleted_at"},
+				{IndexName: "idx_users_email", Unique: true, ColumnName: "tenant_id"},
+				{IndexName: "idx_users_email", Unique: true, ColumnName: "email"},
+				{IndexName: "idx_users_team_id", ColumnName: "team_id"},
+				{IndexName: "idx_users_tenant_id", ColumnName: "tenant_id"},
+			},
+			foreignKeys: []ForeignKey{{Name: "fk_users_team_id", Column: "team_id", RefTable: "teams", RefColumn: "id"}},
+		},
+	}}
+
+	actual, err := introspect(database, desired)
+	if err != nil {
+		t.Fatalf("introspect returned %v", err)
+	}
+	if len(actual.Tables) != 1 || actual.Tables[0].Name != "users" {
+		t.Fatalf("introspected %+v, want the users table only", actual.Tables)
+	}
+	users := actual.Tables[0]
+	if email, _ := users.column("email"); !email.NotNull || email.PrimaryKey {
+		t.Errorf("email = %+v, want a NOT NULL column outside the primary key", email)
+	}
+	if index := users.Indexes[1]; index.Name != "idx_users_email" || !slices.Equal(index.Columns, []string{"tenant_id", "email"}) {
+		t.Errorf("index = %+v, want idx_users_email on tenant_id, email", index)
+	}
+
+	want := []string{"teams: table is missing"}
+	if differences := describeDifferences(actual, desired); !slices.Equal(differences, want) {
+		t.Errorf("describeDifferences =\n%s\nwant\n%s", strings.Join(differences, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Drift reads back as differences
+	drifted := database.tables["users"]
+	drifted.columns = slices.Clone(drifted.columns)
+	drifted.columns[3] = column("balance", "double precision", "float8", "YES")
+	legacyDefault := "'none'::character varying"
+	legacy := column("legacy_code", "character varying", "varchar", "YES")
+	legacy.ColumnDefault = &legacyDefault
+	drifted.columns = append(drifted.columns, legacy)
+	drifted.indexes = drifted.indexes[:2]
+	database.tables["users"] = drifted
+
+	actual, err = introspect(database, desired)
+	if err != nil {
+		t.Fatalf("introspect returned %v", err)
+	}
+	want = []string{
+		"users: column balance is double precision, the config implies decimal",
+		"users: column legacy_code varchar DEFAULT 'none' is not in the config",
+		"users: index idx_users_email differs: unique on tenant_id, the config implies unique on tenant_id, email",
+		"users: index idx_users_team_id is missing",
+		"users: index idx_users_tenant_id is missing",
+		"teams: table is missing",
+	}
+	differences := describeDifferences(actual, desired)
+	slices.Sort(differences)
+	slices.Sort(want)
+	if !slices.Equal(differences, want) {
+		t.Errorf("describeDifferences =\n%s\nwant\n%s", strings.Join(differences, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := introspect(fakeCatalog{err: errors.New("connection reset")}, desired); err == nil || err.Error() != "failed to read the columns of users: connection reset" {
+		t.Errorf("introspect returned %v, want the error reading the columns", err)
+	}
+}
+
+// TestDiffDatabase runs db diff -write against the Postgres of SQL_DB_URI, in a schema dropped
+// afterwards. It runs the catalog queries TestIntrospect stands in for.
+func TestDiffDatabase(t *testing.T) {
+	dsn := os.Getenv("SQL_DB_URI")
+	if dsn == "" {
+		t.Skip("SQL_DB_URI is not set")
+	}
+
+	schemaName := fmt.Sprintf("gstructify_diff_%d", time.Now().UnixNano())
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schemaName).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schemaName + " CASCADE") })
+
+	// The generated scripts name tables without a schema
+	scoped := dsn + " search_path=" + schemaName
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		scoped = dsn + separator + "search_path=" + schemaName
+	}
+	db, err := gorm.Open(postgres.Open(scoped), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := func(statements ...string) {
+		t.Helper()
+		for _, statement := range statements {
+			if strings.HasPrefix(statement, "--") {
+				continue
+			}
+			if err := db.Exec(statement).Error; err != nil {
+				t.Fatalf("%s: %v", statement, err)
+			}
+		}
+	}
+
+	// The tables the config implies read back without differences
+	actual, desired := driftedUsers()
+	for _, migration := range planMigrations(Schema{}, desired) {
+		exec(migration.up...)
+	}
+	introspected, err := introspect(postgresCatalog{db: admin, schemaName: schemaName}, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if differences := describeDifferences(introspected, desired); len(differences) > 0 {
+		t.Fatalf("the created tables differ from the config:\n%s", strings.Join(differences, "\n"))
+	}
+
+	// Drift them, then reconcile them with the migrations -write generates
+	users, _ := actual.table("users")
+	exec(
+		"DROP TABLE teams CASCADE;",
+		"ALTER TABLE users ALTER COLUMN age TYPE integer;",
+		"ALTER TABLE users ALTER COLUMN email DROP NOT NULL;",
+		"ALTER TABLE users ADD COLUMN legacy_code varchar;",
+		"DROP INDEX idx_users_email;",
+		users.Indexes[len(users.Indexes)-1].create("users"),
+	)
+
+	wd := t.TempDir()
+	config, err := json.Marshal(Config{Entities: []Entity{
+		entityWith("user",
+			Field{FieldName: "email", Type: "string", Required: true, Unique: true},
+			Field{FieldName: "age", Type: "int"},
+			Field{FieldName: "team_id", Type: "string", References: "team"},
+		),
+		entityWith("team"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wd, "gStructify.config.json"), config, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := DiffDatabase(wd, []string{"-dsn", dsn, "-schema", schemaName, "-write"}); err != nil {
+		t.Fatalf("DiffDatabase returned %v", err)
+	}
+
+	scripts, err := filepath.Glob(filepath.Join(wd, migrationsSQLDir, "*.up.sql"))
+	if err != nil || len(scripts) != 2 {
+		t.Fatalf("-write wrote %v, want the create_teams and alter_users migrations", scripts)
+	}
+	slices.Sort(scripts)
+	for _, script := range scripts {
+		data, err := os.ReadFile(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec(string(data)).Error; err != nil {
+			t.Fatalf("%s: %v", filepath.Base(script), err)
+		}
+	}
+
+	introspected, err = introspect(postgresCatalog{db: admin, schemaName: schemaName}, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if differences := describeDifferences(introspected, desired); len(differences) > 0 {
+		t.Errorf("the reconciled tables differ from the config:\n%s", strings.Join(differences, "\n"))
+	}
+}
@@ -2,7 +2,11 @@ module github.com/nanda03dev/gStructify
 
 go 1.22.5
 
-require gorm.io/driver/postgres v1.5.11
+require (
+	github.com/robfig/cron/v3 v3.0.1
+	gorm.io/driver/postgres v1.5.11
+	gorm.io/gorm v1.25.10
+)
 
 require (
 	github.com/jackc/pgpassfile v1.0.0 // indirect
@@ -14,5 +18,4 @@ require (
 	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	gorm.io/gorm v1.25.10 // indirect
 )
@@ -28,6 +28,24 @@ func main() {
 		return
 	}
 
+	// Sub commands, e.g. `gStructify add worker -name=cleanup -schedule="0 3 * * *"`
+	if len(os.Args) > 2 && os.Args[1] == "add" && os.Args[2] == "worker" {
+		if err := AddWorker(wd, packageName, os.Args[3:]); err != nil {
+			fmt.Printf("Error adding worker: %v\n", err)
+			return
+		}
+		fmt.Println("Added worker successfully!")
+		return
+	}
+
+	// `gStructify db diff -dsn=...` compares the database of the service with the config
+	if len(os.Args) > 2 && os.Args[1] == "db" && os.Args[2] == "diff" {
+		if err := DiffDatabase(wd, os.Args[3:]); err != nil {
+			fmt.Printf("Error comparing the database: %v\n", err)
+		}
+		return
+	}
+
 	config, configErr := getConfigFile(wd)
 
 	// Accept the package name as a command-line argument
@@ -42,6 +60,12 @@ func main() {
 	}
 
 	config = getUpdatedConfig(entityName, config)
+	for _, eachEntity := range config.Entities {
+		if err := validatePermissions(eachEntity); err != nil {
+			fmt.Printf("Error in gStructify.config.json: %v\n", err)
+			return
+		}
+	}
 	var entityNames []string = GetEntityNames(config)
 
 	fmt.Printf("\nCreated layers successfully for entities : %v \n", entityNames)
@@ -56,6 +80,11 @@ func main() {
 		CreateNewMS(wd, packageName, eachEntity)
 	}
 
+	// Create and alter the entity tables with SQL migrations rather than AutoMigrate
+	if err := GenerateMigrations(wd, config); err != nil {
+		fmt.Printf("Error generating migrations: %v\n", err)
+	}
+
 	// This will import all required local packages
 	ImportAllPacakges(wd)
 
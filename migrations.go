@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// migrationsSQLDir holds the migrations of the generated service, see db.Migrator
+const migrationsSQLDir = "sql-migrations/sql"
+
+// migration is a generated pair of scripts, named <epoch>_<name>.up.sql and .down.sql
+type migration struct {
+	name string
+	up   []string
+	down []string
+}
+
+// change is a step of a migration and the statements undoing it
+type change struct {
+	up   []string
+	down []string
+}
+
+// GenerateMigrations writes the SQL migrations that take the schema of the last run to the
+// schema the config implies: a migration creating the table of each new entity and one altering
+// the table of each entity whose fields changed. The schema reached is recorded for the next run.
+func GenerateMigrations(dir string, config Config) error {
+	current, err := loadSnapshot(dir)
+	if err != nil {
+		return err
+	}
+
+	desired := desiredSchema(config)
+	for _, table := range desired.Tables {
+		for _, foreignKey := range table.ForeignKeys {
+			_, known := desired.table(foreignKey.RefTable)
+			_, existing := current.table(foreignKey.RefTable)
+			if !known && !existing {
+				fmt.Printf("Warning: %s.%s references %s, which is not an entity of the config\n", table.Name, foreignKey.Column, foreignKey.RefTable)
+			}
+		}
+	}
+	migrations := planMigrations(current, desired)
+
+	// Tables of entities removed from the config are kept, dropping data is left to a hand
+	// written migration
+	for _, table := range current.Tables {
+		if _, ok := desired.table(table.Name); !ok {
+			fmt.Printf("Warning: table %s has no entity in the config anymore, it is not dropped\n", table.Name)
+			desired.Tables = append(desired.Tables, table)
+		}
+	}
+
+	names, err := writeMigrations(filepath.Join(dir, migrationsSQLDir), migrations)
+	if err != nil {
+		return err
+	}
+	if err := saveSnapshot(dir, desired); err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		fmt.Printf("\nGenerated migrations : %v \n", names)
+	}
+	return nil
+}
+
+// planMigrations returns the migrations from current to desired. Tables are created before the
+// tables referencing them; foreign keys between new tables that reference each other are added
+// by a last migration.
+func planMigrations(current Schema, desired Schema) []migration {
+	var created []Table
+	var migrations []migration
+	for _, table := range desired.Tables {
+		old, ok := current.table(table.Name)
+		if !ok {
+			created = append(created, table)
+			continue
+		}
+		if changes := diffTable(old, table); len(changes) > 0 {
+			migrations = append(migrations, newMigration("alter_"+table.Name, changes))
+		}
+	}
+
+	exists := func(name string) bool {
+		_, ok := current.table(name)
+		return ok
+	}
+
+	var creates []migration
+	var deferred []change
+	for len(created) > 0 {
+		// The first table whose references all exist, or the first one left when they form a cycle
+		next := 0
+		for index, table := range created {
+			if slices.IndexFunc(table.ForeignKeys, func(foreignKey ForeignKey) bool {
+				return foreignKey.RefTable != table.Name && !exists(foreignKey.RefTable) && slices.ContainsFunc(created, func(other Table) bool {
+					return other.Name == foreignKey.RefTable
+				})
+			}) < 0 {
+				next = index
+				break
+			}
+		}
+		table := created[next]
+		created = slices.Delete(created, next, next+1)
+
+		changes := []change{createTable(table)}
+		for _, foreignKey := range table.ForeignKeys {
+			addition := addForeignKey(table.Name, foreignKey)
+			if foreignKey.RefTable == table.Name || exists(foreignKey.RefTable) {
+				changes = append(changes, addition)
+			} else {
+				deferred = append(deferred, addition)
+			}
+		}
+		creates = append(creates, newMigration("create_"+table.Name, changes))
+
+		current.Tables = append(current.Tables, table)
+	}
+	if len(deferred) > 0 {
+		creates = append(creates, newMigration("add_foreign_keys", deferred))
+	}
+
+	return append(creates, migrations...)
+}
+
+// newMigration undoes the changes in reverse order
+func newMigration(name string, changes []change) migration {
+	migration := migration{name: name}
+	for index, change := range changes {
+		migration.up = append(migration.up, change.up...)
+		migration.down = append(migration.down, changes[len(changes)-1-index].down...)
+	}
+	return migration
+}
+
+func createTable(table Table) change {
+	var definitions []string
+	for _, column := range table.Columns {
+		definitions = append(definitions, "\t"+column.definition())
+	}
+	var primaryKey []string
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			primaryKey = append(primaryKey, column.Name)
+		}
+	}
+	if len(primaryKey) > 0 {
+		definitions = append(definitions, "\tPRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+
+	up := []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);", table.Name, strings.Join(definitions, ",\n"))}
+	for _, index := range table.Indexes {
+		up = append(up, index.create(table.Name))
+	}
+	return change{up: up, down: []string{fmt.Sprintf("DROP TABLE IF EXISTS %s;", table.Name)}}
+}
+
+// diffTable returns the changes from old to table. Constraints and indexes are dropped before
+// the columns they cover and created after them.
+func diffTable(old Table, table Table) []change {
+	var changes []change
+
+	for _, foreignKey := range old.ForeignKeys {
+		if other, ok := table.foreignKey(foreignKey.Name); !ok || other != foreignKey {
+			addition := addForeignKey(table.Name, foreignKey)
+			changes = append(changes, change{up: addition.down, down: addition.up})
+		}
+	}
+	for _, index := range old.Indexes {
+		if other, ok := table.index(index.Name); !ok || !other.equal(index) {
+			changes = append(changes, change{
+				up:   []string{fmt.Sprintf("DROP INDEX IF EXISTS %s;", index.Name)},
+				down: []string{index.create(table.Name)},
+			})
+		}
+	}
+	for _, column := range old.Columns {
+		if _, ok := table.column(column.Name); !ok {
+			addition := addColumn(table.Name, column)
+			changes = append(changes, change{
+				up:   addition.down,
+				down: append([]string{fmt.Sprintf("-- The data of %s.%s is not restored", table.Name, column.Name)}, addition.up...),
+			})
+		}
+	}
+	for _, column := range table.Columns {
+		if other, ok := old.column(column.Name); ok {
+			changes = append(changes, alterColumn(table.Name, other, column)...)
+		}
+	}
+	for _, column := range table.Columns {
+		if _, ok := old.column(column.Name); !ok {
+			changes = append(changes, addColumn(table.Name, column))
+		}
+	}
+	for _, index := range table.Indexes {
+		if other, ok := old.index(index.Name); !ok || !other.equal(index) {
+			changes = append(changes, change{
+				up:   []string{index.create(table.Name)},
+				down: []string{fmt.Sprintf("DROP INDEX IF EXISTS %s;", index.Name)},
+			})
+		}
+	}
+	for _, foreignKey := range table.ForeignKeys {
+		if other, ok := old.foreignKey(foreignKey.Name); !ok || other != foreignKey {
+			changes = append(changes, addForeignKey(table.Name, foreignKey))
+		}
+	}
+
+	return changes
+}
+
+// addColumn adds column to a table that may have rows, a NOT NULL column without default is
+// filled with the zero value of its type
+func addColumn(table string, column Column) change {
+	up := []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s;", table, column.definition())}
+	if zero, ok := zeroValues[column.Type]; ok && column.NotNull && column.Default == "" {
+		withDefault := column
+		withDefault.Default = zero
+		up = []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s;", table, withDefault.definition()),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column.Name),
+		}
+	}
+	return change{up: up, down: []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, column.Name)}}
+}
+
+func alterColumn(table string, old Column, column Column) []change {
+	var changes []change
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", table, column.Name)
+
+	if old.Type != column.Type {
+		changes = append(changes, change{
+			up:   []string{fmt.Sprintf("%s TYPE %s USING %s::%s;", alter, column.Type, column.Name, column.Type)},
+			down: []string{fmt.Sprintf("%s TYPE %s USING %s::%s;", alter, old.Type, column.Name, old.Type)},
+		})
+	}
+	if old.Default != column.Default {
+		changes = append(changes, change{up: setDefault(alter, column.Default), down: setDefault(alter, old.Default)})
+	}
+	if old.NotNull != column.NotNull {
+		changes = append(changes, change{up: setNotNull(table, column), down: setNotNull(table, old)})
+	}
+	return changes
+}
+
+func setDefault(alter string, value string) []string {
+	if value == "" {
+		return []string{alter + " DROP DEFAULT;"}
+	}
+	return []string{alter + " SET DEFAULT " + value + ";"}
+}
+
+// setNotNull makes column nullable or not, filling its NULLs with the zero value of its type first
+func setNotNull(table string, column Column) []string {
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", table, column.Name)
+	if !column.NotNull {
+		return []string{alter + " DROP NOT NULL;"}
+	}
+	statements := []string{}
+	if zero, ok := zeroValues[column.Type]; ok {
+		statements = append(statements, fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL;", table, column.Name, zero, column.Name))
+	}
+	return append(statements, alter+" SET NOT NULL;")
+}
+
+// addForeignKey drops the constraint first, Postgres has no ADD CONSTRAINT IF NOT EXISTS
+func addForeignKey(table string, foreignKey ForeignKey) change {
+	drop := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", table, foreignKey.Name)
+	return change{
+		up: []string{
+			drop,
+			fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s);",
+				table, foreignKey.Name, foreignKey.Column, foreignKey.RefTable, foreignKey.RefColumn),
+		},
+		down: []string{drop},
+	}
+}
+
+// writeMigrations writes migrations to dir, one second apart and after the migrations already
+// there so they apply in order
+func writeMigrations(dir string, migrations []migration) ([]string, error) {
+	if len(migrations) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	epoch := time.Now().UTC().Truncate(time.Second)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if len(entry.Name()) < 14 {
+			continue
+		}
+		if last, err := time.Parse("20060102150405", entry.Name()[:14]); err == nil && !last.Before(epoch) {
+			epoch = last.Add(time.Second)
+		}
+	}
+
+	var names []string
+	for index, migration := range migrations {
+		name := FormatEpoch(epoch.Add(time.Duration(index)*time.Second)) + "_" + migration.name
+		scripts := map[string][]string{name + ".up.sql": migration.up, name + ".down.sql": migration.down}
+		for file, statements := range scripts {
+			content := "-- Generated by gStructify from gStructify.config.json\n\n" + strings.Join(statements, "\n") + "\n"
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+				return nil, err
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
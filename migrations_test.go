@@ -0,0 +1,519 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func entityWith(name string, fields ...Field) Entity {
+	return Entity{EntityName: name, Fields: fields}
+}
+
+func schemaOf(entities ...Entity) Schema {
+	return desiredSchema(Config{Entities: entities})
+}
+
+func migrationNames(migrations []migration) []string {
+	var names []string
+	for _, migration := range migrations {
+		names = append(names, migration.name)
+	}
+	return names
+}
+
+func TestPlanMigrations(t *testing.T) {
+	user := entityWith("user", Field{FieldName: "email", Type: "string", Required: true, Unique: true})
+	order := entityWith("order", Field{FieldName: "user_id", Type: "string", References: "user"})
+	// Two entities referencing each other cannot both be created first
+	team := entityWith("team", Field{FieldName: "captain_id", Type: "string", References: "player"})
+	player := entityWith("player", Field{FieldName: "team_id", Type: "string", References: "team"})
+	category := entityWith("category", Field{FieldName: "parent_id", Type: "string", References: "category"})
+
+	tests := []struct {
+		name      string
+		current   Schema
+		desired   Schema
+		wantNames []string
+	}{
+		{
+			name:    "nothing changed",
+			current: schemaOf(user, order),
+			desired: schemaOf(user, order),
+		},
+		{
+			name:      "referenced tables first",
+			desired:   schemaOf(order, user),
+			wantNames: []string{"create_users", "create_orders"},
+		},
+		{
+			name:      "reference to an existing table",
+			current:   schemaOf(user),
+			desired:   schemaOf(order, user),
+			wantNames: []string{"create_orders"},
+		},
+		{
+			name:      "self reference",
+			desired:   schemaOf(category),
+			wantNames: []string{"create_categories"},
+		},
+		{
+			name:      "reference cycle",
+			desired:   schemaOf(team, player),
+			wantNames: []string{"create_teams", "create_players", "add_foreign_keys"},
+		},
+		{
+			name:    "creates before alters",
+			current: schemaOf(user),
+			desired: schemaOf(
+				entityWith("user", Field{FieldName: "email", Type: "string", Required: true, Unique: true}, Field{FieldName: "age", Type: "int"}),
+				order,
+			),
+			wantNames: []string{"create_orders", "alter_users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations := planMigrations(tt.current, tt.desired)
+			if names := migrationNames(migrations); !slices.Equal(names, tt.wantNames) {
+				t.Fatalf("migrations = %v, want %v", names, tt.wantNames)
+			}
+			assertReversible(t, tt.current, tt.desired, migrations)
+		})
+	}
+}
+
+func TestPlanMigrationsDefersForeignKeysOfCycles(t *testing.T) {
+	desired := schemaOf(
+		entityWith("team", Field{FieldName: "captain_id", Type: "string", References: "player"}),
+		entityWith("player", Field{FieldName: "team_id", Type: "string", References: "team"}),
+	)
+	migrations := planMigrations(Schema{}, desired)
+
+	if up := strings.Join(migrations[0].up, "\n"); strings.Contains(up, "FOREIGN KEY") {
+		t.Errorf("create_teams references players before they exist:\n%s", up)
+	}
+	if up := strings.Join(migrations[1].up, "\n"); !strings.Contains(up, "fk_players_team_id") {
+		t.Errorf("create_players does not reference the teams created before it:\n%s", up)
+	}
+	if up := strings.Join(migrations[2].up, "\n"); !strings.Contains(up, "fk_teams_captain_id") {
+		t.Errorf("add_foreign_keys does not add the deferred constraint:\n%s", up)
+	}
+}
+
+func TestDiffTable(t *testing.T) {
+	base := []Field{
+		{FieldName: "name", Type: "string"},
+		{FieldName: "age", Type: "int32"},
+	}
+	with := func(fields ...Field) Table {
+		return desiredTable(entityWith("user", fields...))
+	}
+
+	tests := []struct {
+		name     string
+		old      Table
+		table    Table
+		wantUp   []string
+		wantDown []string
+	}{
+		{
+			name:  "nothing changed",
+			old:   with(base...),
+			table: with(base...),
+		},
+		{
+			name:   "column added",
+			old:    with(base...),
+			table:  with(append(base, Field{FieldName: "nickname", Type: "string"})...),
+			wantUp: []string{"ALTER TABLE users ADD COLUMN IF NOT EXISTS nickname text;"},
+			wantDown: []string{
+				"ALTER TABLE users DROP COLUMN IF EXISTS nickname;",
+			},
+		},
+		{
+			name:   "column dropped",
+			old:    with(base...),
+			table:  with(base[0]),
+			wantUp: []string{"ALTER TABLE users DROP COLUMN IF EXISTS age;"},
+			wantDown: []string{
+				"-- The data of users.age is not restored",
+				"ALTER TABLE users ADD COLUMN IF NOT EXISTS age integer;",
+			},
+		},
+		{
+			name:     "type changed",
+			old:      with(base...),
+			table:    with(base[0], Field{FieldName: "age", Type: "int"}),
+			wantUp:   []string{"ALTER TABLE users ALTER COLUMN age TYPE bigint USING age::bigint;"},
+			wantDown: []string{"ALTER TABLE users ALTER COLUMN age TYPE integer USING age::integer;"},
+		},
+		{
+			name:  "made required",
+			old:   with(base...),
+			table: with(Field{FieldName: "name", Type: "string", Required: true}, base[1]),
+			wantUp: []string{
+				"UPDATE users SET name = '' WHERE name IS NULL;",
+				"ALTER TABLE users ALTER COLUMN name SET NOT NULL;",
+			},
+			wantDown: []string{"ALTER TABLE users ALTER COLUMN name DROP NOT NULL;"},
+		},
+		{
+			name:     "made optional",
+			old:      with(Field{FieldName: "name", Type: "string", Required: true}, base[1]),
+			table:    with(base...),
+			wantUp:   []string{"ALTER TABLE users ALTER COLUMN name DROP NOT NULL;"},
+			wantDown: []string{"UPDATE users SET name = '' WHERE name IS NULL;", "ALTER TABLE users ALTER COLUMN name SET NOT NULL;"},
+		},
+		{
+			name:     "made unique",
+			old:      with(base...),
+			table:    with(Field{FieldName: "name", Type: "string", Unique: true}, base[1]),
+			wantUp:   []string{"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_name ON users (name);"},
+			wantDown: []string{"DROP INDEX IF EXISTS idx_users_name;"},
+		},
+		{
+			name:  "unique made a plain index",
+			old:   with(Field{FieldName: "name", Type: "string", Unique: true}, base[1]),
+			table: with(Field{FieldName: "name", Type: "string", Index: true}, base[1]),
+			wantUp: []string{
+				"DROP INDEX IF EXISTS idx_users_name;",
+				"CREATE INDEX IF NOT EXISTS idx_users_name ON users (name);",
+			},
+			wantDown: []string{
+				"DROP INDEX IF EXISTS idx_users_name;",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_name ON users (name);",
+			},
+		},
+		{
+			name:  "reference dropped with its column",
+			old:   with(append(base, Field{FieldName: "team_id", Type: "string", References: "team"})...),
+			table: with(base...),
+			wantUp: []string{
+				"ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_team_id;",
+				"DROP INDEX IF EXISTS idx_users_team_id;",
+				"ALTER TABLE users DROP COLUMN IF EXISTS team_id;",
+			},
+			wantDown: []string{
+				"-- The data of users.team_id is not restored",
+				"ALTER TABLE users ADD COLUMN IF NOT EXISTS team_id text;",
+				"CREATE INDEX IF NOT EXISTS idx_users_team_id ON users (team_id);",
+				"ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_team_id;",
+				"ALTER TABLE users ADD CONSTRAINT fk_users_team_id FOREIGN KEY (team_id) REFERENCES teams (id);",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := diffTable(tt.old, tt.table)
+			if len(changes) == 0 {
+				if tt.wantUp != nil {
+					t.Fatalf("diffTable returned no changes, want %v", tt.wantUp)
+				}
+				return
+			}
+
+			alter := newMigration("alter_users", changes)
+			if !slices.Equal(alter.up, tt.wantUp) {
+				t.Errorf("up =\n%s\nwant\n%s", strings.Join(alter.up, "\n"), strings.Join(tt.wantUp, "\n"))
+			}
+			if !slices.Equal(alter.down, tt.wantDown) {
+				t.Errorf("down =\n%s\nwant\n%s", strings.Join(alter.down, "\n"), strings.Join(tt.wantDown, "\n"))
+			}
+
+			// The referenced table must exist for the constraints to apply
+			teams := Table{Name: "teams", Columns: []Column{{Name: "id", Type: "text", NotNull: true, PrimaryKey: true}}}
+			assertReversible(t, Schema{Tables: []Table{tt.old, teams}}, Schema{Tables: []Table{tt.table, teams}}, []migration{alter})
+		})
+	}
+}
+
+func TestAddColumn(t *testing.T) {
+	tests := []struct {
+		name   string
+		column Column
+		wantUp []string
+	}{
+		{
+			name:   "nullable",
+			column: Column{Name: "nickname", Type: "text"},
+			wantUp: []string{"ALTER TABLE users ADD COLUMN IF NOT EXISTS nickname text;"},
+		},
+		{
+			name:   "not null, filled with the zero value",
+			column: Column{Name: "age", Type: "bigint", NotNull: true},
+			wantUp: []string{
+				"ALTER TABLE users ADD COLUMN IF NOT EXISTS age bigint NOT NULL DEFAULT 0;",
+				"ALTER TABLE users ALTER COLUMN age DROP DEFAULT;",
+			},
+		},
+		{
+			name:   "not null with its own default",
+			column: Column{Name: "tenant_id", Type: "text", NotNull: true, Default: "''"},
+			wantUp: []string{"ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id text NOT NULL DEFAULT '';"},
+		},
+		{
+			name:   "not null without a zero value",
+			column: Column{Name: "location", Type: "point", NotNull: true},
+			wantUp: []string{"ALTER TABLE users ADD COLUMN IF NOT EXISTS location point NOT NULL;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := addColumn("users", tt.column)
+			if !slices.Equal(change.up, tt.wantUp) {
+				t.Errorf("up =\n%s\nwant\n%s", strings.Join(change.up, "\n"), strings.Join(tt.wantUp, "\n"))
+			}
+			wantDown := []string{fmt.Sprintf("ALTER TABLE users DROP COLUMN IF EXISTS %s;", tt.column.Name)}
+			if !slices.Equal(change.down, wantDown) {
+				t.Errorf("down = %v, want %v", change.down, wantDown)
+			}
+		})
+	}
+}
+
+func TestWriteMigrations(t *testing.T) {
+	dir := t.TempDir()
+	// A migration written later than now, the generated ones must sort after it
+	if err := os.WriteFile(filepath.Join(dir, "29991231235959_manual.up.sql"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := writeMigrations(dir, planMigrations(Schema{}, schemaOf(entityWith("user"), entityWith("order"))))
+	if err != nil {
+		t.Fatalf("writeMigrations returned %v", err)
+	}
+	want := []string{"30000101000000_create_users", "30000101000001_create_orders"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+
+	for _, name := range names {
+		for _, suffix := range []string{".up.sql", ".down.sql"} {
+			data, err := os.ReadFile(filepath.Join(dir, name+suffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), "-- Generated by gStructify") {
+				t.Errorf("%s%s has no header:\n%s", name, suffix, data)
+			}
+		}
+	}
+
+	if names, err := writeMigrations(dir, nil); err != nil || names != nil {
+		t.Errorf("writeMigrations without migrations = %v, %v, want nothing written", names, err)
+	}
+}
+
+// assertReversible applies the up scripts of migrations to current, which must give desired, then
+// their down scripts in reverse, which must give current back
+func assertReversible(t *testing.T, current Schema, desired Schema, migrations []migration) {
+	t.Helper()
+
+	schema := cloneSchema(current)
+	for _, migration := range migrations {
+		for _, statement := range migration.up {
+			applyStatement(t, &schema, statement)
+		}
+	}
+	if got, want := normalizeSchema(schema), normalizeSchema(desired); !reflect.DeepEqual(got, want) {
+		t.Fatalf("up scripts give\n%s\nwant\n%s", got, want)
+	}
+
+	for index := len(migrations) - 1; index >= 0; index-- {
+		for _, statement := range migrations[index].down {
+			applyStatement(t, &schema, statement)
+		}
+	}
+	if got, want := normalizeSchema(schema), normalizeSchema(current); !reflect.DeepEqual(got, want) {
+		t.Fatalf("down scripts give\n%s\nwant\n%s", got, want)
+	}
+}
+
+var (
+	createTablePattern      = regexp.MustCompile(`(?s)^CREATE TABLE IF NOT EXISTS (\w+) \(\n(.*)\n\);$`)
+	dropTablePattern        = regexp.MustCompile(`^DROP TABLE IF EXISTS (\w+);$`)
+	createIndexPattern      = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX IF NOT EXISTS (\w+) ON (\w+) \(([\w, ]+)\);$`)
+	dropIndexPattern        = regexp.MustCompile(`^DROP INDEX IF EXISTS (\w+);$`)
+	addColumnPattern        = regexp.MustCompile(`^ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (.+);$`)
+	dropColumnPattern       = regexp.MustCompile(`^ALTER TABLE (\w+) DROP COLUMN IF EXISTS (\w+);$`)
+	alterColumnPattern      = regexp.MustCompile(`^ALTER TABLE (\w+) ALTER COLUMN (\w+) (.+);$`)
+	addForeignKeyPattern    = regexp.MustCompile(`^ALTER TABLE (\w+) ADD CONSTRAINT (\w+) FOREIGN KEY \((\w+)\) REFERENCES (\w+) \((\w+)\);$`)
+	dropConstraintPattern   = regexp.MustCompile(`^ALTER TABLE (\w+) DROP CONSTRAINT IF EXISTS (\w+);$`)
+	columnDefinitionPattern = regexp.MustCompile(`^(\w+) (\S+)( NOT NULL)?( DEFAULT (.+))?$`)
+	updateNullsPattern      = regexp.MustCompile(`^UPDATE (\w+) SET (\w+) = .+ WHERE (\w+) IS NULL;$`)
+)
+
+// applyStatement applies one generated statement to schema, failing on statements that would
+// fail in Postgres, like altering a missing column
+func applyStatement(t *testing.T, schema *Schema, statement string) {
+	t.Helper()
+
+	table := func(name string) *Table {
+		for index := range schema.Tables {
+			if schema.Tables[index].Name == name {
+				return &schema.Tables[index]
+			}
+		}
+		t.Fatalf("%s: table %s does not exist", statement, name)
+		return nil
+	}
+	column := func(table *Table, name string) *Column {
+		for index := range table.Columns {
+			if table.Columns[index].Name == name {
+				return &table.Columns[index]
+			}
+		}
+		t.Fatalf("%s: column %s.%s does not exist", statement, table.Name, name)
+		return nil
+	}
+	parseColumn := func(definition string) Column {
+		match := columnDefinitionPattern.FindStringSubmatch(strings.TrimSpace(definition))
+		if match == nil {
+			t.Fatalf("%s: invalid column definition %q", statement, definition)
+		}
+		return Column{Name: match[1], Type: match[2], NotNull: match[3] != "", Default: match[5]}
+	}
+
+	switch {
+	case strings.HasPrefix(statement, "--"):
+	case updateNullsPattern.MatchString(statement):
+		match := updateNullsPattern.FindStringSubmatch(statement)
+		column(table(match[1]), match[2])
+	case createTablePattern.MatchString(statement):
+		match := createTablePattern.FindStringSubmatch(statement)
+		if _, ok := schema.table(match[1]); ok {
+			return
+		}
+		created := Table{Name: match[1]}
+		for _, line := range strings.Split(match[2], ",\n") {
+			line = strings.TrimSpace(line)
+			if primaryKey, ok := strings.CutPrefix(line, "PRIMARY KEY ("); ok {
+				for _, name := range strings.Split(strings.TrimSuffix(primaryKey, ")"), ", ") {
+					column(&created, name).PrimaryKey = true
+				}
+				continue
+			}
+			created.Columns = append(created.Columns, parseColumn(line))
+		}
+		schema.Tables = append(schema.Tables, created)
+	case dropTablePattern.MatchString(statement):
+		name := dropTablePattern.FindStringSubmatch(statement)[1]
+		for _, other := range schema.Tables {
+			for _, foreignKey := range other.ForeignKeys {
+				if foreignKey.RefTable == name && other.Name != name {
+					t.Fatalf("%s: %s is referenced by %s", statement, name, foreignKey.Name)
+				}
+			}
+		}
+		schema.Tables = slices.DeleteFunc(schema.Tables, func(table Table) bool { return table.Name == name })
+	case createIndexPattern.MatchString(statement):
+		match := createIndexPattern.FindStringSubmatch(statement)
+		indexed := table(match[3])
+		if _, ok := indexed.index(match[2]); ok {
+			return
+		}
+		columns := strings.Split(match[4], ", ")
+		for _, name := range columns {
+			column(indexed, name)
+		}
+		indexed.Indexes = append(indexed.Indexes, Index{Name: match[2], Columns: columns, Unique: match[1] != ""})
+	case dropIndexPattern.MatchString(statement):
+		name := dropIndexPattern.FindStringSubmatch(statement)[1]
+		for index := range schema.Tables {
+			schema.Tables[index].Indexes = slices.DeleteFunc(schema.Tables[index].Indexes, func(index Index) bool { return index.Name == name })
+		}
+	case addColumnPattern.MatchString(statement):
+		match := addColumnPattern.FindStringSubmatch(statement)
+		altered := table(match[1])
+		added := parseColumn(match[2])
+		if _, ok := altered.column(added.Name); !ok {
+			altered.Columns = append(altered.Columns, added)
+		}
+	case dropColumnPattern.MatchString(statement):
+		match := dropColumnPattern.FindStringSubmatch(statement)
+		altered := table(match[1])
+		for _, index := range altered.Indexes {
+			if slices.Contains(index.Columns, match[2]) {
+				t.Fatalf("%s: %s still covers the column", statement, index.Name)
+			}
+		}
+		for _, foreignKey := range altered.ForeignKeys {
+			if foreignKey.Column == match[2] {
+				t.Fatalf("%s: %s still covers the column", statement, foreignKey.Name)
+			}
+		}
+		altered.Columns = slices.DeleteFunc(altered.Columns, func(column Column) bool { return column.Name == match[2] })
+	case addForeignKeyPattern.MatchString(statement):
+		match := addForeignKeyPattern.FindStringSubmatch(statement)
+		altered := table(match[1])
+		column(altered, match[3])
+		column(table(match[4]), match[5])
+		if _, ok := altered.foreignKey(match[2]); ok {
+			t.Fatalf("%s: constraint %s already exists", statement, match[2])
+		}
+		altered.ForeignKeys = append(altered.ForeignKeys, ForeignKey{Name: match[2], Column: match[3], RefTable: match[4], RefColumn: match[5]})
+	case dropConstraintPattern.MatchString(statement):
+		match := dropConstraintPattern.FindStringSubmatch(statement)
+		altered := table(match[1])
+		altered.ForeignKeys = slices.DeleteFunc(altered.ForeignKeys, func(foreignKey ForeignKey) bool { return foreignKey.Name == match[2] })
+	case alterColumnPattern.MatchString(statement):
+		match := alterColumnPattern.FindStringSubmatch(statement)
+		altered := column(table(match[1]), match[2])
+		action := match[3]
+		switch {
+		case strings.HasPrefix(action, "TYPE "):
+			altered.Type = strings.Fields(action)[1]
+		case strings.HasPrefix(action, "SET DEFAULT "):
+			altered.Default = strings.TrimPrefix(action, "SET DEFAULT ")
+		case action == "DROP DEFAULT":
+			altered.Default = ""
+		case action == "SET NOT NULL":
+			altered.NotNull = true
+		case action == "DROP NOT NULL":
+			altered.NotNull = false
+		default:
+			t.Fatalf("unknown ALTER COLUMN action %q", action)
+		}
+	default:
+		t.Fatalf("unknown statement %q", statement)
+	}
+}
+
+func cloneSchema(schema Schema) Schema {
+	var clone Schema
+	for _, table := range schema.Tables {
+		table.Columns = slices.Clone(table.Columns)
+		table.Indexes = slices.Clone(table.Indexes)
+		table.ForeignKeys = slices.Clone(table.ForeignKeys)
+		clone.Tables = append(clone.Tables, table)
+	}
+	return clone
+}
+
+// normalizeSchema describes schema regardless of the order of its tables, columns, indexes and
+// constraints, which migrations do not keep
+func normalizeSchema(schema Schema) string {
+	var tables []string
+	for _, table := range schema.Tables {
+		var lines []string
+		for _, column := range table.Columns {
+			lines = append(lines, fmt.Sprintf("  column %s primary=%t", column.definition(), column.PrimaryKey))
+		}
+		for _, index := range table.Indexes {
+			lines = append(lines, "  index "+index.Name+" "+describeIndex(index))
+		}
+		for _, foreignKey := range table.ForeignKeys {
+			lines = append(lines, fmt.Sprintf("  foreign key %s (%s) %s (%s)", foreignKey.Name, foreignKey.Column, foreignKey.RefTable, foreignKey.RefColumn))
+		}
+		slices.Sort(lines)
+		tables = append(tables, table.Name+"\n"+strings.Join(lines, "\n"))
+	}
+	slices.Sort(tables)
+	return strings.Join(tables, "\n")
+}
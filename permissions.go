@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// replacePermissions fills the policy placeholders of the authorization template from the
+// entity's "permissions" block
+func replacePermissions(content string, entity Entity) string {
+	if !strings.Contains(content, "TEMPLATE_POLICY_RULES") {
+		return content
+	}
+
+	var ownerField, ownerColumn string
+	var rules []string
+
+	if permissions := entity.Permissions; permissions != nil {
+		if permissions.OwnerField != "" {
+			ownerField = ToUpperFirst(snakeToCamelCase(permissions.OwnerField))
+			ownerColumn = CamelToSnake(ownerField)
+		}
+
+		operations := []struct {
+			name string
+			rule *PermissionRule
+		}{
+			{"OPERATION_CREATE", permissions.Create},
+			{"OPERATION_READ", permissions.Read},
+			{"OPERATION_LIST", permissions.List},
+			{"OPERATION_UPDATE", permissions.Update},
+			{"OPERATION_DELETE", permissions.Delete},
+		}
+
+		for _, operation := range operations {
+			if operation.rule == nil {
+				continue
+			}
+			if operation.rule.Owner && ownerField == "" {
+				fmt.Printf("Warning: %s %s has an owner rule but no owner_field, only roles and scopes will apply\n", entity.EntityName, operation.name)
+			}
+			rules = append(rules, fmt.Sprintf("%s: {Roles: %s, Scopes: %s, Owner: %t},",
+				operation.name, goStringSlice(operation.rule.Roles), goStringSlice(operation.rule.Scopes), operation.rule.Owner))
+		}
+	}
+
+	content = strings.ReplaceAll(content, "TEMPLATE_OWNER_FIELD", ownerField)
+	content = strings.ReplaceAll(content, "TEMPLATE_OWNER_COLUMN", ownerColumn)
+	content = strings.ReplaceAll(content, "TEMPLATE_POLICY_RULES", strings.Join(rules, "\n\t\t"))
+
+	return content
+}
+
+// validatePermissions checks that the owner_field of the entity's "permissions" block names one
+// of its string fields, as owners are matched by comparing it with the principal's subject
+func validatePermissions(entity Entity) error {
+	permissions := entity.Permissions
+	if permissions == nil || permissions.OwnerField == "" {
+		return nil
+	}
+
+	ownerColumn := columnName(permissions.OwnerField)
+	if entity.AuditFields && slices.Contains([]string{"created_by", "updated_by", "deleted_by"}, ownerColumn) {
+		return nil
+	}
+	for _, field := range entity.Fields {
+		if columnName(field.FieldName) != ownerColumn {
+			continue
+		}
+		if field.Type != "string" {
+			return fmt.Errorf("%s owner_field %s has type %s, it must be a string to match the subject of the principal", entity.EntityName, permissions.OwnerField, field.Type)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s owner_field %s is not a field of the entity", entity.EntityName, permissions.OwnerField)
+}
+
+func goStringSlice(values []string) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
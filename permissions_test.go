@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestValidatePermissions(t *testing.T) {
+	owned := func(ownerField string, fields ...Field) Entity {
+		return Entity{EntityName: "order", Permissions: &Permissions{OwnerField: ownerField}, Fields: fields}
+	}
+	audited := owned("createdBy")
+	audited.AuditFields = true
+
+	tests := []struct {
+		name    string
+		entity  Entity
+		wantErr string
+	}{
+		{name: "no permissions", entity: Entity{EntityName: "order"}},
+		{name: "no owner field", entity: owned("")},
+		{name: "string field", entity: owned("userId", Field{FieldName: "user_id", Type: "string"})},
+		{name: "audit field", entity: audited},
+		{
+			name:    "int field",
+			entity:  owned("user_id", Field{FieldName: "user_id", Type: "int"}),
+			wantErr: "order owner_field user_id has type int, it must be a string to match the subject of the principal",
+		},
+		{
+			name:    "unknown field",
+			entity:  owned("user_id", Field{FieldName: "amount", Type: "string"}),
+			wantErr: "order owner_field user_id is not a field of the entity",
+		},
+		{
+			name:    "audit field without audit fields",
+			entity:  owned("created_by"),
+			wantErr: "order owner_field created_by is not a field of the entity",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePermissions(tt.entity)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validatePermissions returned %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("validatePermissions returned %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
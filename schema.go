@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// schemaSnapshotFile records the schema the generated migrations lead to, the next run diffs the
+// config against it
+const schemaSnapshotFile = "sql-migrations/gStructify.schema.json"
+
+// Schema is the set of entity tables, as implied by the config or found in a database
+type Schema struct {
+	Tables []Table `json:"tables"`
+}
+
+type Table struct {
+	Name        string       `json:"name"`
+	Columns     []Column     `json:"columns"`
+	Indexes     []Index      `json:"indexes"`
+	ForeignKeys []ForeignKey `json:"foreign_keys"`
+}
+
+type Column struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	NotNull    bool   `json:"not_null"`
+	Default    string `json:"default,omitempty"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+}
+
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+type ForeignKey struct {
+	Name      string `json:"name"`
+	Column    string `json:"column"`
+	RefTable  string `json:"ref_table"`
+	RefColumn string `json:"ref_column"`
+}
+
+// namingStrategy names tables and columns like GORM does in the generated service
+var namingStrategy = schema.NamingStrategy{}
+
+func tableName(entityName string) string {
+	return namingStrategy.TableName(ToUpperFirst(entityName))
+}
+
+func columnName(fieldName string) string {
+	return namingStrategy.ColumnName("", ToUpperFirst(snakeToCamelCase(fieldName)))
+}
+
+// sqlTypes maps the Go types of fields to the Postgres types GORM gives them
+var sqlTypes = map[string]string{
+	"string":    "text",
+	"bool":      "boolean",
+	"int":       "bigint",
+	"int64":     "bigint",
+	"uint":      "bigint",
+	"uint64":    "bigint",
+	"uint32":    "bigint",
+	"int32":     "integer",
+	"uint16":    "integer",
+	"int16":     "smallint",
+	"int8":      "smallint",
+	"uint8":     "smallint",
+	"float64":   "decimal",
+	"float32":   "decimal",
+	"time.Time": "timestamptz",
+	"[]byte":    "bytea",
+	"[]string":  "text[]",
+}
+
+// zeroValues fill NOT NULL columns added to tables that already have rows
+var zeroValues = map[string]string{
+	"text":        "''",
+	"boolean":     "false",
+	"bigint":      "0",
+	"integer":     "0",
+	"smallint":    "0",
+	"decimal":     "0",
+	"timestamptz": "now()",
+	"bytea":       "''",
+	"text[]":      "'{}'",
+	"jsonb":       "'null'",
+}
+
+// desiredSchema returns the tables the entities of config map to, the columns of the template
+// models included
+func desiredSchema(config Config) Schema {
+	var desired Schema
+	for _, entity := range config.Entities {
+		desired.Tables = append(desired.Tables, desiredTable(entity))
+	}
+	return desired
+}
+
+func desiredTable(entity Entity) Table {
+	table := Table{Name: tableName(entity.EntityName)}
+	table.Columns = append(table.Columns, Column{Name: "id", Type: "text", NotNull: true, PrimaryKey: true})
+
+	for _, field := range entity.Fields {
+		if TrimLowerCase(field.FieldName) == "id" || field.FieldName == "" {
+			continue
+		}
+
+		name := columnName(field.FieldName)
+		fieldType := field.Type
+		if len(fieldType) <= 2 {
+			fieldType = "any"
+		}
+		sqlType, ok := sqlTypes[fieldType]
+		if !ok {
+			fmt.Printf("Warning: %s.%s has type %s, which has no SQL type, storing it as jsonb\n", entity.EntityName, field.FieldName, fieldType)
+			sqlType = "jsonb"
+		}
+		table.Columns = append(table.Columns, Column{Name: name, Type: sqlType, NotNull: field.Required})
+
+		switch {
+		case field.Unique && entity.MultiTenant:
+			// Tenants do not share values, two tenants may hold the same one
+			table.Indexes = append(table.Indexes, Index{Name: indexName(table.Name, name), Columns: []string{"tenant_id", name}, Unique: true})
+		case field.Unique:
+			table.Indexes = append(table.Indexes, Index{Name: indexName(table.Name, name), Columns: []string{name}, Unique: true})
+		case field.Index || field.References != "":
+			table.Indexes = append(table.Indexes, Index{Name: indexName(table.Name, name), Columns: []string{name}})
+		}
+
+		if field.References != "" {
+			if sqlType != "text" {
+				fmt.Printf("Warning: %s.%s references %s but is a %s, ids are strings\n", entity.EntityName, field.FieldName, field.References, fieldType)
+			}
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+				Name:      "fk_" + table.Name + "_" + name,
+				Column:    name,
+				RefTable:  tableName(field.References),
+				RefColumn: "id",
+			})
+		}
+	}
+
+	if entity.MultiTenant {
+		table.Columns = append(table.Columns, Column{Name: "tenant_id", Type: "text", NotNull: true, Default: "''"})
+		table.Indexes = append(table.Indexes, Index{Name: indexName(table.Name, "tenant_id"), Columns: []string{"tenant_id"}})
+	}
+	if entity.AuditFields {
+		for _, name := range []string{"created_by", "updated_by", "deleted_by"} {
+			table.Columns = append(table.Columns, Column{Name: name, Type: "text", NotNull: true, Default: "''"})
+		}
+	}
+
+	table.Columns = append(table.Columns,
+		Column{Name: "created_at", Type: "timestamptz"},
+		Column{Name: "updated_at", Type: "timestamptz"},
+		Column{Name: "deleted_at", Type: "timestamptz"},
+	)
+	table.Indexes = append(table.Indexes, Index{Name: indexName(table.Name, "deleted_at"), Columns: []string{"deleted_at"}})
+
+	return table
+}
+
+// indexName names an index like GORM does, so existing tables keep theirs
+func indexName(table string, column string) string {
+	return "idx_" + table + "_" + column
+}
+
+func (s Schema) table(name string) (Table, bool) {
+	for _, table := range s.Tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return Table{}, false
+}
+
+func (t Table) column(name string) (Column, bool) {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+func (t Table) index(name string) (Index, bool) {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			return index, true
+		}
+	}
+	return Index{}, false
+}
+
+func (t Table) foreignKey(name string) (ForeignKey, bool) {
+	for _, foreignKey := range t.ForeignKeys {
+		if foreignKey.Name == name {
+			return foreignKey, true
+		}
+	}
+	return ForeignKey{}, false
+}
+
+// definition is the column as written in CREATE TABLE and ADD COLUMN
+func (c Column) definition() string {
+	definition := c.Name + " " + c.Type
+	if c.NotNull {
+		definition += " NOT NULL"
+	}
+	if c.Default != "" {
+		definition += " DEFAULT " + c.Default
+	}
+	return definition
+}
+
+func (i Index) create(table string) string {
+	unique := ""
+	if i.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s);", unique, i.Name, table, strings.Join(i.Columns, ", "))
+}
+
+func (i Index) equal(other Index) bool {
+	return i.Unique == other.Unique && slices.Equal(i.Columns, other.Columns)
+}
+
+// loadSnapshot reads the schema recorded by the last run, empty on the first one
+func loadSnapshot(dir string) (Schema, error) {
+	var snapshot Schema
+	data, err := os.ReadFile(filepath.Join(dir, schemaSnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("invalid %s: %w", schemaSnapshotFile, err)
+	}
+	return snapshot, nil
+}
+
+func saveSnapshot(dir string, snapshot Schema) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, schemaSnapshotFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
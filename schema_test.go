@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDesiredTable(t *testing.T) {
+	timestamps := []Column{
+		{Name: "created_at", Type: "timestamptz"},
+		{Name: "updated_at", Type: "timestamptz"},
+		{Name: "deleted_at", Type: "timestamptz"},
+	}
+	id := Column{Name: "id", Type: "text", NotNull: true, PrimaryKey: true}
+	deletedAt := Index{Name: "idx_order_items_deleted_at", Columns: []string{"deleted_at"}}
+
+	tests := []struct {
+		name            string
+		entity          Entity
+		wantColumns     []Column
+		wantIndexes     []Index
+		wantForeignKeys []ForeignKey
+	}{
+		{
+			name: "fields",
+			entity: Entity{EntityName: "orderItem", Fields: []Field{
+				{FieldName: "id", Type: "string"},
+				{FieldName: "quantity", Type: "int", Required: true},
+				{FieldName: "unitPrice", Type: "float64"},
+				{FieldName: "tags", Type: "[]string"},
+				{FieldName: "options", Type: "map[string]string"},
+			}},
+			wantColumns: append([]Column{
+				id,
+				{Name: "quantity", Type: "bigint", NotNull: true},
+				{Name: "unit_price", Type: "decimal"},
+				{Name: "tags", Type: "text[]"},
+				{Name: "options", Type: "jsonb"},
+			}, timestamps...),
+			wantIndexes: []Index{deletedAt},
+		},
+		{
+			name: "indexes and references",
+			entity: Entity{EntityName: "orderItem", Fields: []Field{
+				{FieldName: "sku", Type: "string", Unique: true},
+				{FieldName: "batch", Type: "string", Index: true},
+				{FieldName: "order_id", Type: "string", Required: true, References: "order"},
+			}},
+			wantColumns: append([]Column{
+				id,
+				{Name: "sku", Type: "text"},
+				{Name: "batch", Type: "text"},
+				{Name: "order_id", Type: "text", NotNull: true},
+			}, timestamps...),
+			wantIndexes: []Index{
+				{Name: "idx_order_items_sku", Columns: []string{"sku"}, Unique: true},
+				{Name: "idx_order_items_batch", Columns: []string{"batch"}},
+				{Name: "idx_order_items_order_id", Columns: []string{"order_id"}},
+				deletedAt,
+			},
+			wantForeignKeys: []ForeignKey{
+				{Name: "fk_order_items_order_id", Column: "order_id", RefTable: "orders", RefColumn: "id"},
+			},
+		},
+		{
+			name: "multi tenant",
+			entity: Entity{EntityName: "orderItem", MultiTenant: true, Fields: []Field{
+				{FieldName: "sku", Type: "string", Unique: true},
+			}},
+			wantColumns: append([]Column{
+				id,
+				{Name: "sku", Type: "text"},
+				{Name: "tenant_id", Type: "text", NotNull: true, Default: "''"},
+			}, timestamps...),
+			wantIndexes: []Index{
+				{Name: "idx_order_items_sku", Columns: []string{"tenant_id", "sku"}, Unique: true},
+				{Name: "idx_order_items_tenant_id", Columns: []string{"tenant_id"}},
+				deletedAt,
+			},
+		},
+		{
+			name:   "audit fields",
+			entity: Entity{EntityName: "orderItem", AuditFields: true},
+			wantColumns: append([]Column{
+				id,
+				{Name: "created_by", Type: "text", NotNull: true, Default: "''"},
+				{Name: "updated_by", Type: "text", NotNull: true, Default: "''"},
+				{Name: "deleted_by", Type: "text", NotNull: true, Default: "''"},
+			}, timestamps...),
+			wantIndexes: []Index{deletedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := desiredTable(tt.entity)
+			if table.Name != "order_items" {
+				t.Errorf("name = %s, want order_items", table.Name)
+			}
+			if !reflect.DeepEqual(table.Columns, tt.wantColumns) {
+				t.Errorf("columns =\n%+v\nwant\n%+v", table.Columns, tt.wantColumns)
+			}
+			if !reflect.DeepEqual(table.Indexes, tt.wantIndexes) {
+				t.Errorf("indexes =\n%+v\nwant\n%+v", table.Indexes, tt.wantIndexes)
+			}
+			if !reflect.DeepEqual(table.ForeignKeys, tt.wantForeignKeys) {
+				t.Errorf("foreign keys =\n%+v\nwant\n%+v", table.ForeignKeys, tt.wantForeignKeys)
+			}
+		})
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	empty, err := loadSnapshot(dir)
+	if err != nil || len(empty.Tables) != 0 {
+		t.Fatalf("loadSnapshot without a snapshot = %+v, %v, want an empty schema", empty, err)
+	}
+
+	saved := schemaOf(
+		entityWith("user", Field{FieldName: "email", Type: "string", Unique: true}),
+		entityWith("order", Field{FieldName: "user_id", Type: "string", References: "user"}),
+	)
+	if err := saveSnapshot(dir, saved); err != nil {
+		t.Fatalf("saveSnapshot returned %v", err)
+	}
+	loaded, err := loadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("loadSnapshot returned %v", err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("loaded =\n%+v\nwant\n%+v", loaded, saved)
+	}
+}
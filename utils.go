@@ -6,22 +6,66 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Field is a field of an entity. Required, Unique, Index and References shape its column in the
+// generated SQL migrations; References names the entity whose id the field holds.
 type Field struct {
-	FieldName string `json:"field_name"`
-	Type      string `json:"type"`
+	FieldName  string `json:"field_name"`
+	Type       string `json:"type"`
+	Required   bool   `json:"required"`
+	Unique     bool   `json:"unique"`
+	Index      bool   `json:"index"`
+	References string `json:"references"`
+}
+
+// PermissionRule grants an operation to principals with any of the roles or scopes.
+// With Owner any authenticated principal is granted the operation on the records it owns.
+type PermissionRule struct {
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"scopes"`
+	Owner  bool     `json:"owner"`
+}
+
+// Permissions maps the operations of an entity to their rules, operations without a rule are
+// open to every authenticated principal. OwnerField is the field holding the owner's subject.
+type Permissions struct {
+	OwnerField string          `json:"owner_field"`
+	Create     *PermissionRule `json:"create"`
+	Read       *PermissionRule `json:"read"`
+	List       *PermissionRule `json:"list"`
+	Update     *PermissionRule `json:"update"`
+	Delete     *PermissionRule `json:"delete"`
+}
+
+// Cache enables a read cache for an entity. Backend is "memory" (default) or "redis", TTL a Go
+// duration (default "5m"); Filters also caches FindWithFilter results.
+type Cache struct {
+	Backend    string `json:"backend"`
+	TTL        string `json:"ttl"`
+	MaxEntries int    `json:"max_entries"`
+	Filters    bool   `json:"filters"`
 }
 
 type Entity struct {
-	EntityName string  `json:"entity_name"`
-	Fields     []Field `json:"fields"`
+	EntityName   string       `json:"entity_name"`
+	Fields       []Field      `json:"fields"`
+	EventSourced bool         `json:"event_sourced"`
+	MultiTenant  bool         `json:"multi_tenant"`
+	AuditFields  bool         `json:"audit_fields"`
+	Permissions  *Permissions `json:"permissions"`
+	Cache        *Cache       `json:"cache"`
 }
 
 type Config struct {
-	Entities []Entity `json:"entities"`
+	// MultiTenant makes every entity multi tenant
+	MultiTenant bool `json:"multi_tenant"`
+	// AuditFields adds created_by, updated_by and deleted_by to every entity
+	AuditFields bool     `json:"audit_fields"`
+	Entities    []Entity `json:"entities"`
 }
 
 func getUpdatedConfig(entityName string, config Config) Config {
@@ -61,6 +105,11 @@ func getConfigFile(dir string) (Config, error) {
 	if err := json.Unmarshal(fileContent, &config); err != nil {
 		fmt.Println("Error while unmarshal config json")
 	}
+
+	for i := range config.Entities {
+		config.Entities[i].MultiTenant = config.Entities[i].MultiTenant || config.MultiTenant
+		config.Entities[i].AuditFields = config.Entities[i].AuditFields || config.AuditFields
+	}
 	return config, nil
 }
 
@@ -158,6 +207,21 @@ func replaceEntityName(content string, entity Entity) string {
 	content = strings.ReplaceAll(content, "templateEntity", entityName)
 	content = strings.ReplaceAll(content, "ms-name", msName)
 	content = strings.ReplaceAll(content, "EPOCH", GetEpoch())
+	content = strings.ReplaceAll(content, "EVENT_SOURCED", strconv.FormatBool(entity.EventSourced))
+	content = replacePermissions(content, entity)
+	content = replaceCache(content, entity)
+
+	tenantModel := ""
+	if entity.MultiTenant {
+		tenantModel = "\tTenantModel\n"
+	}
+	content = strings.ReplaceAll(content, "\tTENANT_MODEL\n", tenantModel)
+
+	auditModel := ""
+	if entity.AuditFields {
+		auditModel = "\tAuditModel\n"
+	}
+	content = strings.ReplaceAll(content, "\tAUDIT_MODEL\n", auditModel)
 
 	patternString := `#@(.*?)#@`
 	re := regexp.MustCompile(patternString)
@@ -233,13 +297,12 @@ func WriteFileInPath(filePath, content string) error {
 }
 
 func GetEpoch() string {
-	// Get the current time
-	currentTime := time.Now()
-
-	// Format the time as YYYYMMDDHHMMSS
-	formattedTime := currentTime.Format("20060102150405")
+	return FormatEpoch(time.Now())
+}
 
-	return formattedTime
+// FormatEpoch formats t as YYYYMMDDHHMMSS, the prefix of migration names
+func FormatEpoch(t time.Time) string {
+	return t.Format("20060102150405")
 }
 
 func normalizeWhitespace(input string) string {
@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/nanda03dev/go-ms-template/src/core/infrastructure/logger"
+)
+
+// RunTemplateWorker runs on the "TEMPLATE_SCHEDULE" schedule, see ScheduledJobs in scheduler.go
+func RunTemplateWorker(ctx context.Context) error {
+	// The context's logger carries the job name
+	logger.FromContext(ctx).Info("templateWorker running")
+
+	// Add the job logic here. Return an error to have the run logged as failed.
+
+	return nil
+}